@@ -0,0 +1,118 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"math"
+	"time"
+)
+
+// LimitAlgorithm computes the concurrency limit from the observed task executions.
+//
+// ConMan calls Update after each execution of a task, retries included, and
+// serialises the calls, so implementations don't need to be safe for concurrent use.
+// Executions cancelled through their context are not observed.
+type LimitAlgorithm interface {
+	// Update returns the new concurrency limit.
+	//
+	// Parameters:
+	//   - limit: The current concurrency limit
+	//   - latency: How long the execution took
+	//   - err: The execution error, nil if it succeeded
+	//
+	// Returns:
+	//   - int64: The new limit, clamped by ConMan to the configured bounds
+	Update(limit int64, latency time.Duration, err error) int64
+}
+
+// AIMD is an additive-increase/multiplicative-decrease limit algorithm.
+//
+// The limit grows by Increase for each window of successful executions
+// (a window being as many executions as the current limit), and is multiplied
+// by Decrease as soon as an execution fails or is slower than LatencyThreshold.
+type AIMD struct {
+	Increase         float64       // Limit increase per window of successes, defaults to 1
+	Decrease         float64       // Factor applied to the limit on failure, in (0, 1), defaults to 0.9
+	LatencyThreshold time.Duration // Latency above which an execution counts as failed, 0 to disable
+
+	estimate float64
+}
+
+// Update implements LimitAlgorithm.
+func (a *AIMD) Update(limit int64, latency time.Duration, err error) int64 {
+	a.estimate = resetEstimate(a.estimate, limit)
+	if err != nil || (a.LatencyThreshold > 0 && latency > a.LatencyThreshold) {
+		decrease := a.Decrease
+		if decrease <= 0 || decrease >= 1 {
+			decrease = 0.9
+		}
+		a.estimate *= decrease
+		return truncate(a.estimate)
+	}
+	increase := a.Increase
+	if increase <= 0 {
+		increase = 1
+	}
+	a.estimate += increase / a.estimate
+	return truncate(a.estimate)
+}
+
+// Gradient is a latency-based limit algorithm, in the spirit of TCP Vegas.
+//
+// It compares the latency of each execution with the lowest latency observed
+// so far. While latency stays close to that minimum, the downstream isn't
+// queueing work and the limit grows. As latency increases, the limit shrinks
+// proportionally. Each failed execution pulls the limit toward half of its
+// value by the Smoothing weight, so that one failure only trims the limit
+// (by 10% with the default smoothing) while a burst of failures halves it.
+type Gradient struct {
+	Smoothing float64 // Weight of each new sample, in (0, 1], defaults to 0.2
+	Tolerance float64 // Latency increase ratio tolerated before shrinking, ≥ 1, defaults to 1.5
+
+	estimate   float64
+	minLatency time.Duration
+}
+
+// Update implements LimitAlgorithm.
+func (g *Gradient) Update(limit int64, latency time.Duration, err error) int64 {
+	g.estimate = resetEstimate(g.estimate, limit)
+	smoothing := g.Smoothing
+	if smoothing <= 0 || smoothing > 1 {
+		smoothing = 0.2
+	}
+	tolerance := g.Tolerance
+	if tolerance < 1 {
+		tolerance = 1.5
+	}
+
+	var target float64
+	if err != nil {
+		target = g.estimate / 2
+	} else {
+		if latency > 0 && (g.minLatency == 0 || latency < g.minLatency) {
+			g.minLatency = latency
+		}
+		gradient := 1.0
+		if latency > 0 {
+			gradient = math.Max(0.5, math.Min(1, tolerance*float64(g.minLatency)/float64(latency)))
+		}
+		target = g.estimate*gradient + math.Sqrt(g.estimate)
+	}
+	g.estimate = (1-smoothing)*g.estimate + smoothing*target
+	return truncate(g.estimate)
+}
+
+// resetEstimate returns the internal estimate of an algorithm, reset to the
+// actual limit if they diverged (e.g. after clamping or a call to SetLimit)
+func resetEstimate(estimate float64, limit int64) float64 {
+	if truncate(estimate) != limit {
+		return float64(limit)
+	}
+	return estimate
+}
+
+// truncate converts an estimate to a limit, ignoring floating point rounding errors
+func truncate(estimate float64) int64 {
+	return int64(estimate + 1e-9)
+}
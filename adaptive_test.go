@@ -0,0 +1,109 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestAIMD(t *testing.T) {
+	t.Parallel()
+	a := &AIMD{LatencyThreshold: 100 * time.Millisecond}
+
+	limit := int64(10)
+	for range 11 {
+		limit = a.Update(limit, 10*time.Millisecond, nil)
+	}
+	if limit != 11 {
+		t.Errorf("Expected the limit to grow by 1 after a window of successes, got %d", limit)
+	}
+
+	if limit = a.Update(limit, 10*time.Millisecond, errors.New("failure")); limit != 9 {
+		t.Errorf("Expected the limit to decrease to 9 after a failure, got %d", limit)
+	}
+	if limit = a.Update(limit, 200*time.Millisecond, nil); limit != 8 {
+		t.Errorf("Expected the limit to decrease to 8 after a slow execution, got %d", limit)
+	}
+}
+
+func TestGradient(t *testing.T) {
+	t.Parallel()
+	g := &Gradient{}
+
+	limit := int64(10)
+	for range 20 {
+		limit = g.Update(limit, 10*time.Millisecond, nil)
+	}
+	if limit <= 10 {
+		t.Errorf("Expected the limit to grow while latency is stable, got %d", limit)
+	}
+
+	grown := limit
+	for range 20 {
+		limit = g.Update(limit, 100*time.Millisecond, nil)
+	}
+	if limit >= grown {
+		t.Errorf("Expected the limit to shrink as latency increases, got %d (was %d)", limit, grown)
+	}
+
+	before := limit
+	if limit = g.Update(limit, 10*time.Millisecond, errors.New("failure")); limit >= before {
+		t.Errorf("Expected the limit to shrink after a failure, got %d (was %d)", limit, before)
+	}
+	if limit = (&Gradient{}).Update(20, 10*time.Millisecond, errors.New("failure")); limit != 18 {
+		t.Errorf("Expected a single failure to trim the limit by 10%%, got %d (was 20)", limit)
+	}
+}
+
+func TestAdaptiveLimit(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4, WithAdaptiveLimit(2, 6, &AIMD{Increase: 4}))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	for range 20 {
+		cm.Run(ctx, &doubler{operand: 1})
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if cm.Limit() != 6 {
+		t.Errorf("Expected the limit to grow up to its maximum of 6, got %d", cm.Limit())
+	}
+
+	for range 20 {
+		cm.Run(ctx, &errdoubler{operand: 1})
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if cm.Limit() != 2 {
+		t.Errorf("Expected the limit to shrink down to its minimum of 2, got %d", cm.Limit())
+	}
+}
+
+func TestAdaptiveLimitIgnoresCancellations(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(t.Context())
+	cm, err := New[int](4, WithAdaptiveLimit(2, 6, &AIMD{}))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	for range 4 {
+		cm.Run(ctx, &sleepydoubler{operand: 1, delay: time.Second})
+	}
+	cancel()
+	if err := cm.Wait(t.Context()); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if cm.Limit() != 4 {
+		t.Errorf("Expected cancelled executions to leave the limit unchanged, got %d", cm.Limit())
+	}
+}
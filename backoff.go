@@ -0,0 +1,146 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+)
+
+// Backoff computes the delays between the attempts of a retried task.
+//
+// A Backoff is set through RetryConfig.Backoff. As a RetryConfig can be shared
+// by tasks retrying concurrently, implementations must be safe for concurrent use.
+type Backoff interface {
+	// Next returns the delay before a retry.
+	//
+	// Parameters:
+	//   - attempt: The index of the retry, starting at 0 for the first retry
+	//
+	// Returns:
+	//   - time.Duration: The delay to wait before running the task again
+	Next(attempt int) time.Duration
+}
+
+// ConstantBackoff waits the same delay before each retry.
+type ConstantBackoff struct {
+	Delay time.Duration // Delay before each retry
+}
+
+// Next implements Backoff.
+func (b *ConstantBackoff) Next(attempt int) time.Duration {
+	return b.Delay
+}
+
+// LinearBackoff increases the delay by the same step before each retry.
+type LinearBackoff struct {
+	Initial time.Duration // Delay before the first retry
+	Step    time.Duration // Delay increase per retry
+	Max     time.Duration // Maximum delay, 0 for no maximum
+}
+
+// Next implements Backoff.
+func (b *LinearBackoff) Next(attempt int) time.Duration {
+	return capDelay(float64(b.Initial)+float64(b.Step)*float64(attempt), b.Max)
+}
+
+// ExponentialBackoff multiplies the delay by the same factor before each retry.
+type ExponentialBackoff struct {
+	Initial time.Duration // Delay before the first retry
+	Factor  float64       // Multiplier applied to the delay per retry, defaults to 2
+	Max     time.Duration // Maximum delay, 0 for no maximum
+}
+
+// Next implements Backoff.
+func (b *ExponentialBackoff) Next(attempt int) time.Duration {
+	factor := b.Factor
+	if factor <= 0 {
+		factor = 2
+	}
+	return capDelay(float64(b.Initial)*math.Pow(factor, float64(attempt)), b.Max)
+}
+
+// FibonacciBackoff increases the delay following the Fibonacci sequence:
+// Initial, Initial, 2*Initial, 3*Initial, 5*Initial, and so on.
+type FibonacciBackoff struct {
+	Initial time.Duration // Delay before the first retry
+	Max     time.Duration // Maximum delay, 0 for no maximum
+}
+
+// Next implements Backoff.
+func (b *FibonacciBackoff) Next(attempt int) time.Duration {
+	a, n := 1.0, 1.0
+	for range attempt {
+		a, n = n, a+n
+	}
+	return capDelay(float64(b.Initial)*a, b.Max)
+}
+
+// FullJitter waits a random delay between 0 and an exponentially growing
+// ceiling, which spreads the retries of concurrent tasks the most.
+type FullJitter struct {
+	Base time.Duration // Ceiling of the delay before the first retry, doubled per retry
+	Max  time.Duration // Maximum ceiling, 0 for no maximum
+}
+
+// Next implements Backoff.
+func (b *FullJitter) Next(attempt int) time.Duration {
+	return randomDelay(0, exponentialCeiling(b.Base, b.Max, attempt))
+}
+
+// EqualJitter waits half of an exponentially growing ceiling, plus a random
+// delay up to the other half, which guarantees a minimum delay between retries.
+type EqualJitter struct {
+	Base time.Duration // Ceiling of the delay before the first retry, doubled per retry
+	Max  time.Duration // Maximum ceiling, 0 for no maximum
+}
+
+// Next implements Backoff.
+func (b *EqualJitter) Next(attempt int) time.Duration {
+	ceiling := exponentialCeiling(b.Base, b.Max, attempt)
+	return ceiling/2 + randomDelay(0, ceiling-ceiling/2)
+}
+
+// DecorrelatedJitter waits a random delay between Base and a ceiling tripled
+// per retry, starting at three times Base, so that the delay grows with each
+// retry without following a fixed sequence.
+//
+// Each delay is drawn from the attempt alone, so a DecorrelatedJitter shared
+// by tasks retrying concurrently gives each of them its own delays.
+type DecorrelatedJitter struct {
+	Base time.Duration // Minimum delay
+	Max  time.Duration // Maximum delay, 0 for no maximum
+}
+
+// Next implements Backoff.
+func (b *DecorrelatedJitter) Next(attempt int) time.Duration {
+	ceiling := capDelay(float64(b.Base)*math.Pow(3, float64(attempt+1)), 0)
+	return capDelay(float64(randomDelay(b.Base, ceiling)), b.Max)
+}
+
+// exponentialCeiling returns base doubled attempt times, capped at maxDelay
+func exponentialCeiling(base, maxDelay time.Duration, attempt int) time.Duration {
+	return capDelay(float64(base)*math.Pow(2, float64(attempt)), maxDelay)
+}
+
+// randomDelay returns a random delay in [low, high), low if the range is empty
+func randomDelay(low, high time.Duration) time.Duration {
+	if high <= low {
+		return low
+	}
+	return low + rand.N(high-low)
+}
+
+// capDelay converts a delay to a duration capped at maxDelay, if not zero,
+// and at the largest duration to avoid overflows
+func capDelay(delay float64, maxDelay time.Duration) time.Duration {
+	if maxDelay > 0 && delay > float64(maxDelay) {
+		return maxDelay
+	}
+	if delay >= math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(max(delay, 0))
+}
@@ -0,0 +1,123 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func delays(b Backoff, n int) []time.Duration {
+	var d []time.Duration
+	for attempt := range n {
+		d = append(d, b.Next(attempt))
+	}
+	return d
+}
+
+func TestDeterministicBackoffs(t *testing.T) {
+	t.Parallel()
+	ms := time.Millisecond
+	tests := []struct {
+		name     string
+		backoff  Backoff
+		expected []time.Duration
+	}{
+		{"constant", &ConstantBackoff{Delay: 5 * ms}, []time.Duration{5 * ms, 5 * ms, 5 * ms}},
+		{"linear", &LinearBackoff{Initial: 10 * ms, Step: 5 * ms, Max: 22 * ms}, []time.Duration{10 * ms, 15 * ms, 20 * ms, 22 * ms}},
+		{"exponential", &ExponentialBackoff{Initial: ms, Factor: 3}, []time.Duration{ms, 3 * ms, 9 * ms, 27 * ms}},
+		{"exponential default factor", &ExponentialBackoff{Initial: ms, Max: 5 * ms}, []time.Duration{ms, 2 * ms, 4 * ms, 5 * ms}},
+		{"fibonacci", &FibonacciBackoff{Initial: ms, Max: 6 * ms}, []time.Duration{ms, ms, 2 * ms, 3 * ms, 5 * ms, 6 * ms}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if d := delays(tt.backoff, len(tt.expected)); !slices.Equal(d, tt.expected) {
+				t.Errorf("Expected delays %v, got %v", tt.expected, d)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoffOverflow(t *testing.T) {
+	t.Parallel()
+	b := &ExponentialBackoff{Initial: time.Second}
+	if d := b.Next(1000); d <= 0 {
+		t.Errorf("Expected a huge positive delay, got %v", d)
+	}
+}
+
+func TestJitterBackoffs(t *testing.T) {
+	t.Parallel()
+	base, ceiling := 10*time.Millisecond, 80*time.Millisecond
+	full := &FullJitter{Base: base, Max: ceiling}
+	equal := &EqualJitter{Base: base, Max: ceiling}
+	decorrelated := &DecorrelatedJitter{Base: base, Max: ceiling}
+
+	for range 100 {
+		for attempt := range 6 {
+			high := min(ceiling, base<<attempt)
+			if d := full.Next(attempt); d < 0 || d >= high {
+				t.Fatalf("Expected a full jitter delay in [0, %v), got %v", high, d)
+			}
+			if d := equal.Next(attempt); d < high/2 || d >= high {
+				t.Fatalf("Expected an equal jitter delay in [%v, %v), got %v", high/2, high, d)
+			}
+			if d := decorrelated.Next(attempt); d < base || d > ceiling {
+				t.Fatalf("Expected a decorrelated jitter delay in [%v, %v], got %v", base, ceiling, d)
+			}
+		}
+	}
+}
+
+func TestDecorrelatedJitterShared(t *testing.T) {
+	t.Parallel()
+	base := 10 * time.Millisecond
+	b := &DecorrelatedJitter{Base: base}
+	for range 100 {
+		for attempt := range 20 {
+			b.Next(attempt)
+		}
+		for attempt, ceiling := range []time.Duration{3 * base, 9 * base, 27 * base} {
+			if d := b.Next(attempt); d < base || d >= ceiling {
+				t.Fatalf("Expected a delay in [%v, %v) for attempt %d regardless of other retries, got %v", base, ceiling, attempt, d)
+			}
+		}
+	}
+}
+
+// recordedbackoff records the attempts it is asked a delay for
+type recordedbackoff struct {
+	mu       sync.Mutex
+	attempts []int
+}
+
+func (b *recordedbackoff) Next(attempt int) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.attempts = append(b.attempts, attempt)
+	return time.Millisecond
+}
+
+func TestRetryWithBackoff(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	b := &recordedbackoff{}
+	cm.Run(ctx, &stubborndoubler{config: &RetryConfig{MaxAttempts: 3, Backoff: b}})
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if !slices.Equal(b.attempts, []int{0, 1, 2}) {
+		t.Errorf("Expected the backoff to compute the delay of each retry, got %v", b.attempts)
+	}
+	if r := cm.Results()[0]; r.Attempts != 4 {
+		t.Errorf("Expected 4 attempts, got %d", r.Attempts)
+	}
+}
@@ -0,0 +1,229 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned when dispatching a task whose circuit is open
+// (see WithCircuitBreaker).
+var ErrCircuitOpen = errors.New("conman: circuit open")
+
+// CircuitState is the state of the circuit breaker of a key.
+type CircuitState int
+
+const (
+	// CircuitClosed lets tasks through, counting consecutive failures.
+	CircuitClosed CircuitState = iota
+	// CircuitOpen rejects tasks with ErrCircuitOpen until the cool-down elapses.
+	CircuitOpen
+	// CircuitHalfOpen lets a limited number of probe tasks through, to find
+	// out whether the circuit can be closed again.
+	CircuitHalfOpen
+)
+
+// String returns the name of the state.
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return fmt.Sprintf("CircuitState(%d)", int(s))
+	}
+}
+
+// BreakerConfig defines the behavior of the circuit breakers of a ConMan.
+type BreakerConfig struct {
+	FailureThreshold int           // Number of consecutive failures opening the circuit
+	CoolDown         time.Duration // Time the circuit stays open before letting probes through
+	HalfOpenProbes   int           // Number of probes let through while half-open, and of successes closing the circuit, defaults to 1
+}
+
+// validate checks the validity of the BreakerConfig fields
+func (bc *BreakerConfig) validate() error {
+	if bc.FailureThreshold <= 0 {
+		return fmt.Errorf("FailureThreshold must be positive, got %d", bc.FailureThreshold)
+	}
+	if bc.CoolDown <= 0 {
+		return fmt.Errorf("CoolDown must be positive, got %v", bc.CoolDown)
+	}
+	if bc.HalfOpenProbes < 0 {
+		return fmt.Errorf("HalfOpenProbes cannot be negative, got %d", bc.HalfOpenProbes)
+	}
+	return nil
+}
+
+// breakers holds a circuit breaker per task key, created on first use and
+// dropped once closed without failures or tasks in flight
+type breakers struct {
+	mu     sync.Mutex
+	config BreakerConfig
+	keys   map[string]*breaker
+}
+
+// breaker is the circuit breaker of a key. Each state change starts a new
+// generation, so that tasks let through in a previous state don't affect
+// the current one.
+type breaker struct {
+	state      CircuitState
+	generation uint64
+	failures   int
+	openedAt   time.Time
+	probes     int
+	successes  int
+	inflight   int // Tasks let through and not settled yet, whatever their generation
+}
+
+// newBreakers creates the circuit breakers, with defaults applied to config
+func newBreakers(config BreakerConfig) *breakers {
+	config.HalfOpenProbes = max(config.HalfOpenProbes, 1)
+	return &breakers{config: config, keys: make(map[string]*breaker)}
+}
+
+// allow reports whether a task with the given key can be dispatched.
+// It returns the generation of the breaker to pass to settle.
+func (bs *breakers) allow(key string) (uint64, bool) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b := bs.breaker(key)
+	if b.state == CircuitOpen {
+		if time.Since(b.openedAt) < bs.config.CoolDown {
+			return 0, false
+		}
+		b.transition(CircuitHalfOpen)
+	}
+	if b.state == CircuitHalfOpen {
+		if b.probes >= bs.config.HalfOpenProbes {
+			return 0, false
+		}
+		b.probes++
+	}
+	b.inflight++
+	return b.generation, true
+}
+
+// settle reports the outcome of a task let through at the given generation.
+// Neutral outcomes, such as cancelled tasks, only give back the probe, if any.
+func (bs *breakers) settle(key string, generation uint64, failed, neutral bool) {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b := bs.breaker(key)
+	b.inflight--
+	defer bs.prune(key, b)
+	if b.generation != generation {
+		return
+	}
+	switch b.state {
+	case CircuitClosed:
+		if neutral {
+			return
+		}
+		if !failed {
+			b.failures = 0
+		} else if b.failures++; b.failures >= bs.config.FailureThreshold {
+			b.transition(CircuitOpen)
+		}
+	case CircuitHalfOpen:
+		b.probes--
+		if neutral {
+			return
+		}
+		if failed {
+			b.transition(CircuitOpen)
+		} else if b.successes++; b.successes >= bs.config.HalfOpenProbes {
+			b.transition(CircuitClosed)
+		}
+	}
+}
+
+// state returns the state of the breaker of a key, seen from a task
+// dispatched now
+func (bs *breakers) state(key string) CircuitState {
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+	b, ok := bs.keys[key]
+	if !ok {
+		return CircuitClosed
+	}
+	if b.state == CircuitOpen && time.Since(b.openedAt) >= bs.config.CoolDown {
+		return CircuitHalfOpen
+	}
+	return b.state
+}
+
+// breaker returns the breaker of a key, creating it if needed. Must be called with mu held.
+func (bs *breakers) breaker(key string) *breaker {
+	b, ok := bs.keys[key]
+	if !ok {
+		b = &breaker{}
+		bs.keys[key] = b
+	}
+	return b
+}
+
+// prune drops the breaker of a key once it is closed without failures or
+// tasks in flight, as it is then the same as a new one. Must be called with mu held.
+func (bs *breakers) prune(key string, b *breaker) {
+	if b.state == CircuitClosed && b.failures == 0 && b.inflight == 0 {
+		delete(bs.keys, key)
+	}
+}
+
+// transition moves the breaker to a new state, starting a new generation
+func (b *breaker) transition(state CircuitState) {
+	*b = breaker{state: state, generation: b.generation + 1, inflight: b.inflight}
+	if state == CircuitOpen {
+		b.openedAt = time.Now()
+	}
+}
+
+// CircuitState returns the state of the circuit breaker of a key.
+// Keys that never had a task dispatched are closed. An open circuit whose
+// cool-down has elapsed is reported as half-open, as the next task would be
+// let through as a probe.
+//
+// Parameters:
+//   - key: The key of the tasks, as returned by Keyed
+//
+// Returns:
+//   - CircuitState: The state of the circuit, CircuitClosed without WithCircuitBreaker
+func (c *ConMan[T]) CircuitState(key string) CircuitState {
+	if c.breakers == nil {
+		return CircuitClosed
+	}
+	return c.breakers.state(key)
+}
+
+// allowCircuit checks the circuit breaker of a job, if any, and records
+// the generation it was let through at
+func (c *ConMan[T]) allowCircuit(j *job[T]) error {
+	if c.breakers == nil || j.key == "" {
+		return nil
+	}
+	generation, ok := c.breakers.allow(j.key)
+	if !ok {
+		return fmt.Errorf("%w for key %q", ErrCircuitOpen, j.key)
+	}
+	j.circuit = generation
+	return nil
+}
+
+// settleCircuit reports the result of a job to its circuit breaker, if any.
+// Jobs that didn't run and cancelled jobs are neutral.
+func (c *ConMan[T]) settleCircuit(j *job[T], r Result[T]) {
+	if c.breakers == nil || j.key == "" {
+		return
+	}
+	neutral := r.Attempts == 0 || errors.Is(r.Err, context.Canceled)
+	c.breakers.settle(j.key, j.circuit, r.Err != nil, neutral)
+}
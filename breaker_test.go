@@ -0,0 +1,194 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// keyedtask succeeds or fails depending on its flag
+type keyedtask struct {
+	key  string
+	fail bool
+}
+
+func (k *keyedtask) Key() string {
+	return k.key
+}
+
+func (k *keyedtask) Execute(ctx context.Context) (int, error) {
+	if k.fail {
+		return -1, errBoom
+	}
+	return 1, nil
+}
+
+func TestBreakerStates(t *testing.T) {
+	t.Parallel()
+	bs := newBreakers(BreakerConfig{FailureThreshold: 2, CoolDown: 20 * time.Millisecond, HalfOpenProbes: 2})
+
+	settle := func(failed bool) {
+		t.Helper()
+		generation, ok := bs.allow("db")
+		if !ok {
+			t.Fatal("Expected the task to be let through")
+		}
+		bs.settle("db", generation, failed, false)
+	}
+	settle(true)
+	settle(false)
+	settle(true)
+	if s := bs.state("db"); s != CircuitClosed {
+		t.Fatalf("Expected a success to reset the failure count, got %v", s)
+	}
+	settle(true)
+	if s := bs.state("db"); s != CircuitOpen {
+		t.Fatalf("Expected 2 consecutive failures to open the circuit, got %v", s)
+	}
+	if _, ok := bs.allow("db"); ok {
+		t.Fatal("Expected an open circuit to reject tasks")
+	}
+	if s := bs.state("api"); s != CircuitClosed {
+		t.Errorf("Expected other keys to be unaffected, got %v", s)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if s := bs.state("db"); s != CircuitHalfOpen {
+		t.Fatalf("Expected the circuit to be half-open after the cool-down, got %v", s)
+	}
+	first, ok1 := bs.allow("db")
+	second, ok2 := bs.allow("db")
+	if _, ok3 := bs.allow("db"); !ok1 || !ok2 || ok3 {
+		t.Fatalf("Expected exactly 2 probes to be let through, got %v %v %v", ok1, ok2, ok3)
+	}
+	bs.settle("db", first, false, false)
+	if s := bs.state("db"); s != CircuitHalfOpen {
+		t.Fatalf("Expected the circuit to wait for the second probe, got %v", s)
+	}
+	bs.settle("db", second, false, false)
+	if s := bs.state("db"); s != CircuitClosed {
+		t.Fatalf("Expected successful probes to close the circuit, got %v", s)
+	}
+}
+
+func TestBreakerPruning(t *testing.T) {
+	t.Parallel()
+	bs := newBreakers(BreakerConfig{FailureThreshold: 2, CoolDown: time.Second})
+
+	first, _ := bs.allow("db")
+	second, _ := bs.allow("db")
+	bs.settle("db", first, false, false)
+	if _, ok := bs.keys["db"]; !ok {
+		t.Fatal("Expected the breaker to be kept while a task is in flight")
+	}
+	bs.settle("db", second, true, false)
+	if _, ok := bs.keys["db"]; !ok {
+		t.Fatal("Expected the breaker to be kept while it counts failures")
+	}
+	generation, _ := bs.allow("db")
+	bs.settle("db", generation, false, false)
+	if len(bs.keys) != 0 {
+		t.Errorf("Expected the breaker to be dropped once closed without failures, got %d breakers", len(bs.keys))
+	}
+}
+
+func TestBreakerProbeFailure(t *testing.T) {
+	t.Parallel()
+	bs := newBreakers(BreakerConfig{FailureThreshold: 1, CoolDown: 10 * time.Millisecond})
+
+	stale, _ := bs.allow("db")
+	generation, _ := bs.allow("db")
+	bs.settle("db", generation, true, false)
+	time.Sleep(15 * time.Millisecond)
+
+	probe, ok := bs.allow("db")
+	if !ok {
+		t.Fatal("Expected a probe to be let through")
+	}
+	bs.settle("db", stale, false, false)
+	if s := bs.state("db"); s != CircuitHalfOpen {
+		t.Fatalf("Expected tasks let through before the circuit opened to be ignored, got %v", s)
+	}
+	bs.settle("db", probe, false, true)
+	if _, ok := bs.allow("db"); !ok {
+		t.Fatal("Expected a neutral probe to give its place back")
+	}
+	bs.settle("db", probe, true, false)
+	if s := bs.state("db"); s != CircuitOpen {
+		t.Errorf("Expected a failed probe to open the circuit again, got %v", s)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithCircuitBreaker(&BreakerConfig{FailureThreshold: 3, CoolDown: time.Minute}))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	for range 3 {
+		if err := cm.Run(ctx, &keyedtask{key: "db", fail: true}); err != nil {
+			t.Fatalf("Run returned an unexpected error: %v", err)
+		}
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if s := cm.CircuitState("db"); s != CircuitOpen {
+		t.Fatalf("Expected the circuit to be open, got %v", s)
+	}
+
+	if err := cm.Run(ctx, &keyedtask{key: "db"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected ErrCircuitOpen, got %v", err)
+	}
+	if _, err := cm.Submit(ctx, &keyedtask{key: "db"}); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Expected Submit to fail with ErrCircuitOpen, got %v", err)
+	}
+	if err := cm.Run(ctx, &keyedtask{key: "api"}); err != nil {
+		t.Errorf("Expected other keys to be unaffected, got %v", err)
+	}
+	if err := cm.Run(ctx, &doubler{operand: 1}); err != nil {
+		t.Errorf("Expected tasks without a key to be unaffected, got %v", err)
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+}
+
+// keyedsleeper waits for its context to be cancelled
+type keyedsleeper struct {
+	key string
+}
+
+func (k *keyedsleeper) Key() string {
+	return k.key
+}
+
+func (k *keyedsleeper) Execute(ctx context.Context) (int, error) {
+	<-ctx.Done()
+	return -1, ctx.Err()
+}
+
+func TestCircuitBreakerIgnoresCancellation(t *testing.T) {
+	t.Parallel()
+	cm, err := New[int](2, WithCircuitBreaker(&BreakerConfig{FailureThreshold: 1, CoolDown: time.Minute}))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	h, err := cm.Submit(ctx, &keyedsleeper{key: "db"})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	cancel()
+	<-h.Done()
+	if s := cm.CircuitState("db"); s != CircuitClosed {
+		t.Errorf("Expected a cancelled task not to open the circuit, got %v", s)
+	}
+}
@@ -0,0 +1,89 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrRetryBudgetExhausted is the reason of a RetryError when a retry was denied
+// because the retry budget of the ConMan was spent (see WithRetryBudget).
+var ErrRetryBudgetExhausted = errors.New("conman: retry budget exhausted")
+
+// budgetBuckets is the number of buckets the sliding window is divided into
+const budgetBuckets = 10
+
+// retryBudget limits the number of retries to a ratio of the number of tasks
+// started over a sliding window. The window is divided into buckets, which
+// are recycled as they expire.
+type retryBudget struct {
+	mu      sync.Mutex
+	ratio   float64
+	min     int
+	width   time.Duration
+	buckets [budgetBuckets]budgetBucket
+}
+
+// budgetBucket holds the counts of a slice of the window
+type budgetBucket struct {
+	epoch    int64
+	requests int
+	retries  int
+}
+
+// newRetryBudget creates a budget allowing minRetries plus ratio retries per
+// task started over window
+func newRetryBudget(ratio float64, window time.Duration, minRetries int) *retryBudget {
+	return &retryBudget{
+		ratio: ratio,
+		min:   minRetries,
+		width: max(window/budgetBuckets, 1),
+	}
+}
+
+// request records the start of a task
+func (b *retryBudget) request() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.bucket(time.Now()).requests++
+}
+
+// tryRetry records a retry if the budget allows it, and reports whether it does
+func (b *retryBudget) tryRetry() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	requests, retries := b.totals(now)
+	if float64(retries+1) > b.ratio*float64(requests)+float64(b.min) {
+		return false
+	}
+	b.bucket(now).retries++
+	return true
+}
+
+// bucket returns the bucket of the given time, reset if it expired.
+// Must be called with mu held.
+func (b *retryBudget) bucket(now time.Time) *budgetBucket {
+	epoch := now.UnixNano() / int64(b.width)
+	bk := &b.buckets[epoch%budgetBuckets]
+	if bk.epoch != epoch {
+		*bk = budgetBucket{epoch: epoch}
+	}
+	return bk
+}
+
+// totals returns the number of tasks started and retries over the window
+// ending at the given time. Must be called with mu held.
+func (b *retryBudget) totals(now time.Time) (requests, retries int) {
+	epoch := now.UnixNano() / int64(b.width)
+	for _, bk := range b.buckets {
+		if epoch-bk.epoch < budgetBuckets {
+			requests += bk.requests
+			retries += bk.retries
+		}
+	}
+	return requests, retries
+}
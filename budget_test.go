@@ -0,0 +1,79 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetryBudget(t *testing.T) {
+	t.Parallel()
+	b := newRetryBudget(0.5, time.Minute, 1)
+	for range 4 {
+		b.request()
+	}
+	for i := range 3 {
+		if !b.tryRetry() {
+			t.Fatalf("Expected retry %d to be allowed", i+1)
+		}
+	}
+	if b.tryRetry() {
+		t.Error("Expected the fourth retry to exceed the budget")
+	}
+	b.request()
+	b.request()
+	if !b.tryRetry() {
+		t.Error("Expected new tasks to grow the budget")
+	}
+}
+
+func TestRetryBudgetWindow(t *testing.T) {
+	t.Parallel()
+	b := newRetryBudget(1, 50*time.Millisecond, 0)
+	b.request()
+	if !b.tryRetry() || b.tryRetry() {
+		t.Fatal("Expected a single retry to be allowed")
+	}
+	time.Sleep(60 * time.Millisecond)
+	if b.tryRetry() {
+		t.Error("Expected the tasks started before the window not to count")
+	}
+	b.request()
+	if !b.tryRetry() {
+		t.Error("Expected the retries made before the window not to count")
+	}
+}
+
+func TestRetryBudgetExhausted(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](5, WithRetryBudget(0, time.Minute, 2))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	for range 5 {
+		cm.Run(ctx, &stubborndoubler{config: &RetryConfig{MaxAttempts: 1}})
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+
+	denied := 0
+	for _, r := range cm.Results() {
+		if errors.Is(r.Err, ErrRetryBudgetExhausted) {
+			denied++
+			if r.Attempts != 1 || !errors.Is(r.Err, errBoom) {
+				t.Errorf("Expected a denied task to keep its error after a single attempt, got %+v", r)
+			}
+		} else if r.Attempts != 2 {
+			t.Errorf("Expected an allowed task to be retried once, got %+v", r)
+		}
+	}
+	if denied != 3 {
+		t.Errorf("Expected 3 retries to be denied, got %d", denied)
+	}
+}
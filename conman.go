@@ -31,22 +31,48 @@ package conman
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
-	"math/rand/v2"
+	"runtime/debug"
 	"sync"
 	"time"
 )
 
+// ErrAborted is returned by Run when the ConMan stopped accepting tasks
+// after a task failure in fail-fast mode (see WithFailFast).
+var ErrAborted = errors.New("conman: aborted after a task failure")
+
+// ErrAtCapacity is returned by TryRun when the concurrency limit is reached.
+var ErrAtCapacity = errors.New("conman: concurrency limit reached")
+
+// ErrClosed is returned when dispatching a task to a closed ConMan.
+var ErrClosed = errors.New("conman: closed")
+
 // ConMan a structure to manage multiple tasks running
 // concurrently while ensuring the total number of running
 // tasks doesn't exceed a certain concurrency limit
 type ConMan[T any] struct {
-	wg      sync.WaitGroup
-	mu      sync.Mutex
-	errors  []error
-	outputs []T
-	buffer  chan any
+	wg       sync.WaitGroup
+	workers  sync.WaitGroup
+	mu       sync.Mutex
+	errors   []error
+	outputs  []T
+	limiter  *limiter
+	results  []Result[T]
+	opts     options
+	seq      int
+	slots    []Result[T]
+	stream   chan Result[T]
+	ctx      context.Context
+	cancel   context.CancelCauseFunc
+	failure  error
+	closed   bool
+	done     chan struct{}
+	queue    *queue[T]
+	budget   *retryBudget
+	breakers *breakers
+	bucket   *tokenBucket
+	keys     *keyLimits
 }
 
 // New creates a new ConMan instance with the specified concurrency limit.
@@ -56,10 +82,12 @@ type ConMan[T any] struct {
 //
 // Parameters:
 //   - concurrencyLimit: Maximum number of concurrent tasks (must be ≥ 2)
+//   - opts: Optional settings, see the With* functions
 //
 // Returns:
 //   - *ConMan[T]: A new ConMan instance
-//   - error: An error if concurrencyLimit is less than 2
+//   - error: An error if concurrencyLimit is less than 2, options are invalid
+//     or a worker fails to initialize
 //
 // Example:
 //
@@ -67,15 +95,51 @@ type ConMan[T any] struct {
 //	if err != nil {
 //		return fmt.Errorf("failed to create ConMan: %w", err)
 //	}
-func New[T any](concurrencyLimit int64) (*ConMan[T], error) {
+func New[T any](concurrencyLimit int64, opts ...Option) (*ConMan[T], error) {
 	if concurrencyLimit < 2 {
 		return nil, fmt.Errorf("concurrencyLimit must be at least 2, got %d", concurrencyLimit)
 	}
-	return &ConMan[T]{
-		buffer:  make(chan any, concurrencyLimit),
+	c := &ConMan[T]{
 		outputs: make([]T, 0, concurrencyLimit), // Preallocate for all tasks
 		errors:  make([]error, 0),               // Let errors grow as needed (typically fewer)
-	}, nil
+		done:    make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+	if err := c.opts.validate(); err != nil {
+		return nil, err
+	}
+	c.limiter = newLimiter(concurrencyLimit, c.opts.aging)
+	if p := c.opts.pool; p != nil && c.opts.queue == nil {
+		c.opts.queue = &queueSettings{depth: p.size, policy: OverflowBlock}
+	}
+	if q := c.opts.queue; q != nil {
+		c.queue = newQueue[T](q.depth, q.policy, c.opts.aging)
+	}
+	if a := c.opts.adaptive; a != nil {
+		c.limiter.resize(min(max(concurrencyLimit, a.min), a.max))
+	}
+	if b := c.opts.retryBudget; b != nil {
+		c.budget = newRetryBudget(b.ratio, b.window, b.min)
+	}
+	if b := c.opts.breaker; b != nil {
+		c.breakers = newBreakers(*b)
+	}
+	if r := c.opts.rateLimit; r != nil {
+		c.bucket = newTokenBucket(r.tokens, r.interval, r.burst)
+	}
+	if k := c.opts.keyLimits; k != nil {
+		c.keys = newKeyLimits(k.limit, k.overrides, c.opts.aging)
+	}
+	c.ctx, c.cancel = context.WithCancelCause(context.Background())
+	if p := c.opts.pool; p != nil {
+		if err := c.startWorkers(p.size); err != nil {
+			c.cancel(err)
+			return nil, err
+		}
+	}
+	return c, nil
 }
 
 // Task defines the interface that all executable tasks must implement.
@@ -96,34 +160,261 @@ type Task[T any] interface {
 	Execute(ctx context.Context) (T, error)
 }
 
+// job is a task admitted for execution, along with its dispatch settings
+type job[T any] struct {
+	ticket
+	ctx      context.Context
+	cancel   context.CancelFunc
+	task     Task[T]
+	handle   *Handle[T]
+	weight   int64
+	seq      int
+	key      string
+	limitKey string
+	circuit  uint64
+	unwatch  func() bool // Stops watching the context of a queued job
+}
+
+// Prioritized can be implemented by tasks to set their scheduling priority.
+//
+// In priority mode (see WithPriorityScheduling), when the concurrency limit is
+// reached, tasks with a higher priority get free slots first. Tasks that don't
+// implement this interface have a priority of 0.
+type Prioritized interface {
+	// Priority returns the priority of the task, higher values run first.
+	Priority() int
+}
+
+// Weighted can be implemented by tasks to consume more than one slot.
+//
+// A task with a weight of N reserves N units of the concurrency limit while it
+// runs, like a weighted semaphore. A task heavier than the concurrency limit,
+// which can change at runtime, runs alone once no other task is running.
+// Tasks that don't implement this interface have a weight of 1.
+type Weighted interface {
+	// Weight returns the number of slots used by the task, at least 1.
+	Weight() int64
+}
+
+// Keyed can be implemented by tasks to associate them with a key, such as the
+// name of the downstream service they call.
+//
+// With WithCircuitBreaker, tasks sharing a key share a circuit breaker. Tasks
+// that don't implement this interface, or return an empty key, are not subject
+// to circuit breaking.
+type Keyed interface {
+	// Key returns the key of the task.
+	Key() string
+}
+
+// LimitKeyed can be implemented by tasks to associate them with a limit key,
+// such as the tenant they work for.
+//
+// With WithKeyLimits, tasks sharing a limit key share a concurrency limit.
+// Limit keys are independent from the keys of Keyed, so that a task can be
+// limited per tenant while sharing a circuit breaker per downstream service.
+// Tasks that don't implement this interface, or return an empty limit key,
+// are only subject to the global concurrency limit.
+type LimitKeyed interface {
+	// LimitKey returns the limit key of the task.
+	LimitKey() string
+}
+
 // Run executes a task concurrently, respecting the concurrency limit.
 //
-// If the concurrency limit is reached, this method blocks until a slot becomes available.
+// If the concurrency limit is reached, this method blocks until a slot becomes available
+// or ctx is cancelled. Blocked calls get slots in call order, or by task priority in priority mode.
 // The task runs in a separate goroutine and results are collected automatically.
 //
+// In queue mode (see WithQueue and WithWorkerPool), the task is queued and Run returns
+// without waiting for a slot, unless the queue is full. Tasks are then taken from the queue as slots
+// become available. If a queued task can't be started, e.g. because its context is
+// cancelled, the error is recorded as the task's result.
+//
 // Parameters:
 //   - ctx: Context for cancellation and timeout control
 //   - t: Task implementing the Task[T] interface
 //
 // Returns:
 //   - error: Context cancellation error if ctx is cancelled before task starts
+//     ErrAborted if a task failed in fail-fast mode
+//     ErrClosed if the ConMan is closed
+//     ErrQueueFull if the queue is full with the OverflowReject policy
+//     ErrCircuitOpen if the circuit of the task key is open
+//     An error if the task weight is less than 1
 //     Returns nil if task is successfully dispatched
 //
 // Note: This method only returns errors related to task dispatch.
 //
 //	Task execution errors are collected and accessible via Errors().
 func (c *ConMan[T]) Run(ctx context.Context, t Task[T]) error {
+	return c.dispatch(ctx, t, nil, true)
+}
+
+// TryRun executes a task concurrently if a slot is available right away.
+//
+// Unlike Run, this method never blocks. If the concurrency limit is reached,
+// or other tasks are already waiting for a slot, the task is not dispatched.
+// In queue mode, the task is queued if there is room in the queue, or handled
+// according to the overflow policy. With OverflowBlock, a full queue makes
+// TryRun return ErrAtCapacity.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - t: Task implementing the Task[T] interface
+//
+// Returns:
+//   - error: ErrAtCapacity if the task can't be dispatched right away
+//     Any other error returned by Run
+//     Returns nil if task is successfully dispatched
+func (c *ConMan[T]) TryRun(ctx context.Context, t Task[T]) error {
+	return c.dispatch(ctx, t, nil, false)
+}
+
+// Submit executes a task concurrently, like Run, and returns a handle to it.
+//
+// The handle can be used to wait for the task, read its result or cancel it,
+// independently of the other tasks. The result is also collected like with Run.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control
+//   - t: Task implementing the Task[T] interface
+//
+// Returns:
+//   - *Handle[T]: Handle to the dispatched task, nil if dispatch failed
+//   - error: Context cancellation error if ctx is cancelled before task starts
+//
+// Example:
+//
+//	h, err := cm.Submit(ctx, &myTask{})
+//	if err != nil {
+//		return err
+//	}
+//	result, err := h.Await(ctx)
+func (c *ConMan[T]) Submit(ctx context.Context, t Task[T]) (*Handle[T], error) {
+	h := newHandle[T]()
+	if err := c.dispatch(ctx, t, h, true); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// dispatch admits a task and runs it in a separate goroutine once it gets its slots.
+// The handle h is optional and completed with the task result.
+// If block is false, dispatch fails with ErrAtCapacity instead of waiting.
+func (c *ConMan[T]) dispatch(ctx context.Context, t Task[T], h *Handle[T], block bool) error {
 	if err := ctx.Err(); err != nil {
 		return err
 	}
-	c.reserveOne()
-	go func() {
-		defer c.releaseOne()
-		c.executeTask(ctx, t)
-	}()
+	weight, err := c.weight(t)
+	if err != nil {
+		return err
+	}
+	if err := c.admit(); err != nil {
+		return err
+	}
+	j := &job[T]{task: t, handle: h, weight: weight, key: c.key(t), limitKey: c.limitKey(t)}
+	if err := c.allowCircuit(j); err != nil {
+		c.wg.Done()
+		return err
+	}
+	j.priority = c.priority(t)
+	j.ctx, j.cancel = c.taskContext(ctx)
+	if h != nil {
+		h.cancel = j.cancel
+	}
+
+	if c.queue != nil {
+		err = c.enqueue(j, block)
+	} else if err = c.reserve(j, block); err == nil {
+		j.seq = c.nextSeq()
+		go c.run(j)
+	}
+	if err != nil {
+		c.settleCircuit(j, Result[T]{Err: err})
+		j.cancel()
+		c.wg.Done()
+		if c.ctx.Err() != nil {
+			return c.rejection()
+		}
+		return err
+	}
 	return nil
 }
 
+// run executes a job while holding its slots, then releases them
+func (c *ConMan[T]) run(j *job[T]) {
+	defer c.releaseKey(j)
+	defer c.limiter.release(j.weight)
+	c.finish(j, c.executeTask(j.ctx, j.seq, j.task))
+}
+
+// finish records the result of a job and marks it as done
+func (c *ConMan[T]) finish(j *job[T], r Result[T]) {
+	c.settleCircuit(j, r)
+	c.record(r, c.cancelled(j, r.Err))
+	if j.handle != nil {
+		j.handle.complete(r)
+	}
+	j.cancel()
+	c.wg.Done()
+}
+
+// cancelled reports whether a job failed because its own context was cancelled,
+// through its handle or the caller's context, rather than the ConMan's context
+func (c *ConMan[T]) cancelled(j *job[T], err error) bool {
+	return errors.Is(err, context.Canceled) && j.ctx.Err() != nil && c.ctx.Err() == nil
+}
+
+// admit increments the wait group for a new task, unless the ConMan
+// stopped accepting tasks. Tasks can't be admitted once closed, which
+// guarantees the wait group won't be incremented after termination.
+func (c *ConMan[T]) admit() error {
+	var err error
+	c.withLock(func() {
+		if err = c.rejectionLocked(); err == nil {
+			c.wg.Add(1)
+		}
+	})
+	return err
+}
+
+// rejection returns the error explaining why the ConMan stopped accepting
+// tasks, nil if it didn't
+func (c *ConMan[T]) rejection() error {
+	var err error
+	c.withLock(func() {
+		err = c.rejectionLocked()
+	})
+	return err
+}
+
+// rejectionLocked is rejection, to be called with the mutex held.
+// It returns ErrClosed once closed, or ErrAborted wrapping the first task
+// failure in fail-fast mode.
+func (c *ConMan[T]) rejectionLocked() error {
+	if c.closed {
+		return ErrClosed
+	}
+	if c.ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ErrAborted, context.Cause(c.ctx))
+	}
+	return nil
+}
+
+// taskContext derives the context of a task from the caller's context.
+// The returned context is also cancelled when the ConMan's own context is.
+func (c *ConMan[T]) taskContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	stop := context.AfterFunc(c.ctx, func() {
+		cancel(context.Cause(c.ctx))
+	})
+	return ctx, func() {
+		stop()
+		cancel(context.Canceled)
+	}
+}
+
 // Wait blocks until all previously dispatched tasks have completed.
 //
 // This method should be called after all Run() calls to ensure all tasks
@@ -134,10 +425,12 @@ func (c *ConMan[T]) Run(ctx context.Context, t Task[T]) error {
 //
 // Returns:
 //   - error: Context cancellation error if ctx is cancelled before all tasks complete
+//     The first task error in fail-fast mode
 //     Returns nil if all tasks complete successfully
 //
 // Note: This method blocks until all tasks finish or context is cancelled.
 // After calling Wait(), you can access results via Outputs() and errors via Errors().
+// Once all tasks are done, the channel returned by Stream() is closed.
 func (c *ConMan[T]) Wait(ctx context.Context) error {
 	done := make(chan struct{})
 
@@ -150,10 +443,120 @@ func (c *ConMan[T]) Wait(ctx context.Context) error {
 	case <-ctx.Done():
 		return ctx.Err()
 	case <-done:
+		c.closeStream()
+		var err error
+		c.withLock(func() {
+			err = c.failure
+		})
+		return err
+	}
+}
+
+// Close stops the ConMan from accepting new tasks.
+//
+// Subsequent calls to Run, TryRun and Submit return ErrClosed. Tasks already
+// dispatched keep running, and the channel returned by Done() is closed once
+// they have all completed. In worker pool mode, the workers stop once the
+// queue is drained. Close is idempotent and always returns nil.
+func (c *ConMan[T]) Close() error {
+	var closing bool
+	c.withLock(func() {
+		closing = !c.closed
+		c.closed = true
+	})
+	if !closing {
+		return nil
+	}
+	go func() {
+		c.wg.Wait()
+		if c.queue != nil {
+			// No job can be queued anymore, let the workers stop
+			c.queue.close()
+		}
+		c.workers.Wait()
+		c.closeStream()
+		close(c.done)
+	}()
+	return nil
+}
+
+// Shutdown closes the ConMan and waits for the running tasks to complete.
+//
+// If ctx is done before all tasks complete, the context of the remaining tasks
+// is cancelled, and Shutdown returns without waiting for them to return.
+// Done() can then be used to wait for the full termination.
+//
+// Parameters:
+//   - ctx: Context bounding the time given to running tasks to complete
+//
+// Returns:
+//   - error: Context error if ctx is done before all tasks complete
+//     Returns nil if all tasks completed
+func (c *ConMan[T]) Shutdown(ctx context.Context) error {
+	c.Close()
+	select {
+	case <-c.done:
 		return nil
+	case <-ctx.Done():
+		c.cancel(ErrClosed)
+		return ctx.Err()
 	}
 }
 
+// Done returns a channel that is closed once the ConMan is closed and all
+// its tasks have completed.
+func (c *ConMan[T]) Done() <-chan struct{} {
+	return c.done
+}
+
+// Stream returns a channel on which each task result is sent as soon as the task completes.
+//
+// Results are only sent for tasks completing after the first call to Stream().
+// The channel buffer is the size of the concurrency limit. Beyond that, a task holds its
+// slot until its result is received, so a slow consumer slows down the execution of tasks.
+// The channel is closed when Wait() returns after all tasks have completed, or on
+// termination after Close(), so Wait() or Close() must be called from another
+// goroutine than the one consuming the stream.
+//
+// Combined with WithoutAccumulation(), this allows processing any number of tasks
+// without keeping their results in memory.
+//
+// Returns:
+//   - <-chan Result[T]: Channel of task results, in completion order
+//
+// Example:
+//
+//	results := cm.Stream()
+//	go func() {
+//		for _, t := range tasks {
+//			cm.Run(ctx, t)
+//		}
+//		cm.Wait(ctx)
+//	}()
+//	for r := range results {
+//		// process r
+//	}
+func (c *ConMan[T]) Stream() <-chan Result[T] {
+	var stream chan Result[T]
+	c.withLock(func() {
+		if c.stream == nil {
+			c.stream = make(chan Result[T], c.limiter.limit())
+		}
+		stream = c.stream
+	})
+	return stream
+}
+
+// closeStream closes the results stream, if any
+func (c *ConMan[T]) closeStream() {
+	c.withLock(func() {
+		if c.stream != nil {
+			close(c.stream)
+			c.stream = nil
+		}
+	})
+}
+
 // Outputs returns a slice of successful task results.
 //
 // Only results from tasks that completed without errors are included.
@@ -184,54 +587,220 @@ func (c *ConMan[T]) Errors() []error {
 	return result
 }
 
-// reserveOne reserves a slot in the concurrency buffer and increments wait group
-func (c *ConMan[T]) reserveOne() {
-	c.buffer <- nil
-	c.wg.Add(1)
+// Results returns the results of all completed tasks, with their metadata.
+//
+// Unlike Outputs() and Errors(), each result keeps the task, its value and
+// its error together, along with the number of attempts and timing information.
+// Results are collected in the order tasks complete, not submission order.
+//
+// Returns:
+//   - []Result[T]: Slice of task results
+func (c *ConMan[T]) Results() []Result[T] {
+	var result []Result[T]
+	c.withLock(func() {
+		result = c.results
+	})
+	return result
 }
 
-// releaseOne decrements wait group and releases a slot from concurrency buffer
-func (c *ConMan[T]) releaseOne() {
-	c.wg.Done()
-	<-c.buffer
+// OrderedResults returns the results of all dispatched tasks in submission order.
+//
+// The result of the task dispatched by the n-th successful call to Run is at
+// index n. Failed tasks keep their slot, with a non-nil Err.
+// This method is only available in ordered mode (see WithOrderedResults)
+// and returns nil otherwise. It should be called after Wait().
+//
+// Returns:
+//   - []Result[T]: Slice of task results, indexed by sequence number
+func (c *ConMan[T]) OrderedResults() []Result[T] {
+	var result []Result[T]
+	c.withLock(func() {
+		result = c.slots
+	})
+	return result
 }
 
-// executeTask runs a single task and handles its result or error
-func (c *ConMan[T]) executeTask(ctx context.Context, t Task[T]) {
-	op, err := t.Execute(ctx)
-	if err == nil {
-		c.withLock(func() {
-			c.outputs = append(c.outputs, op)
-		})
-		return
+// nextSeq assigns the next sequence number and reserves its slot in ordered mode
+func (c *ConMan[T]) nextSeq() int {
+	var seq int
+	c.withLock(func() {
+		seq = c.seq
+		c.seq++
+		if c.opts.ordered {
+			c.slots = append(c.slots, Result[T]{Seq: seq})
+		}
+	})
+	return seq
+}
+
+// SetLimit changes the concurrency limit, while tasks may be running.
+//
+// Growing the limit lets blocked Run calls proceed right away. Shrinking it
+// lets running tasks finish, but holds back new tasks until the number of
+// running tasks drops below the new limit.
+//
+// Parameters:
+//   - limit: New maximum number of concurrent tasks (must be ≥ 2)
+//
+// Returns:
+//   - error: An error if limit is less than 2
+func (c *ConMan[T]) SetLimit(limit int64) error {
+	if limit < 2 {
+		return fmt.Errorf("limit must be at least 2, got %d", limit)
+	}
+	c.limiter.resize(limit)
+	return nil
+}
+
+// Limit returns the current concurrency limit.
+// With WithAdaptiveLimit, it reflects the latest limit computed by the algorithm.
+func (c *ConMan[T]) Limit() int64 {
+	return c.limiter.limit()
+}
+
+// key returns the key of a task, empty if it has none
+func (c *ConMan[T]) key(t Task[T]) string {
+	if k, ok := t.(Keyed); ok {
+		return k.Key()
 	}
+	return ""
+}
 
-	if er, ok := err.(*RetriableError); ok {
-		c.retry(ctx, t, er.RetryConfig)
-		return
+// priority returns the scheduling priority of a task
+func (c *ConMan[T]) priority(t Task[T]) int {
+	if p, ok := t.(Prioritized); ok && c.opts.prioritized {
+		return p.Priority()
 	}
+	return 0
+}
 
-	c.withLock(func() {
-		c.errors = append(c.errors, err)
-	})
+// weight returns the number of slots used by a task
+func (c *ConMan[T]) weight(t Task[T]) (int64, error) {
+	w, ok := t.(Weighted)
+	if !ok {
+		return 1, nil
+	}
+	weight := w.Weight()
+	if weight < 1 {
+		return 0, fmt.Errorf("task weight must be at least 1, got %d", weight)
+	}
+	return weight, nil
+}
+
+// reserve reserves the slot of the key of a job, if any, then its slots in the
+// concurrency limiter, so that a job waiting for its key doesn't hold slots
+// other jobs could use. If block is false, it fails with ErrAtCapacity instead of waiting for slots.
+func (c *ConMan[T]) reserve(j *job[T], block bool) error {
+	if err := c.reserveKey(j, block); err != nil {
+		return err
+	}
+	var err error
+	if !block {
+		if !c.limiter.tryAcquire(j.weight) {
+			err = ErrAtCapacity
+		}
+	} else {
+		err = c.limiter.acquire(j.ctx, j.weight, j.priority)
+	}
+	if err != nil {
+		c.releaseKey(j)
+	}
+	return err
 }
 
-// calculateDelay computes the delay before the next retry attempt
-func (c *ConMan[T]) calculateDelay(attempt int, config *RetryConfig) time.Duration {
-	delay := float64(config.InitialDelay) * math.Pow(config.BackoffFactor, float64(attempt))
-	if int64(delay) > config.MaxDelay {
-		delay = float64(config.MaxDelay)
+// executeTask runs a single task, retrying it if needed, and returns its result
+func (c *ConMan[T]) executeTask(ctx context.Context, seq int, t Task[T]) Result[T] {
+	r := Result[T]{Seq: seq, Task: t, Attempts: 1, Start: time.Now()}
+	if c.budget != nil {
+		c.budget.request()
 	}
-	if config.Jitter {
-		delay = delay * rand.Float64()
+	r.Value, r.Err = c.execute(ctx, t, 1)
+	if er, ok := r.Err.(*RetriableError); ok && er.RetryConfig != nil {
+		var attempts int
+		r.Value, attempts, r.Err = c.retry(ctx, t, er, r.Start)
+		r.Attempts += attempts
 	}
-	return time.Duration(delay) * time.Millisecond
+	r.End = time.Now()
+	return r
 }
 
-// waitForNextAttempt waits for the calculated delay before the next retry attempt
-func (c *ConMan[T]) waitForNextAttempt(ctx context.Context, attempt int, config *RetryConfig) error {
-	delay := c.calculateDelay(attempt, config)
+// execute runs a single attempt of a task and reports it to the adaptive limit, if any.
+// The attempt waits for a token of the rate limit, if any, and its error is made
+// retriable if it matches a retry policy. The attempt number, starting at 1, is
+// passed to the task through its context.
+func (c *ConMan[T]) execute(ctx context.Context, t Task[T], attempt int) (T, error) {
+	if c.bucket != nil {
+		if err := c.bucket.acquire(ctx); err != nil {
+			var zero T
+			return zero, err
+		}
+	}
+	ctx = context.WithValue(ctx, attemptKey{}, attempt)
+	start := time.Now()
+	op, err := c.executeWithTimeout(ctx, t)
+	c.adapt(time.Since(start), err)
+	return op, c.classify(err)
+}
 
+// protectedExecute runs a single attempt of a task, recovering from any panic.
+// A panic is converted into a *PanicError, made retriable if configured so.
+func (c *ConMan[T]) protectedExecute(ctx context.Context, t Task[T]) (op T, err error) {
+	defer func() {
+		if v := recover(); v != nil {
+			err = &PanicError{Value: v, Stack: debug.Stack()}
+			if c.opts.panicRetry != nil {
+				err = &RetriableError{Err: err, RetryConfig: c.opts.panicRetry}
+			}
+		}
+	}()
+	return t.Execute(ctx)
+}
+
+// adapt updates the concurrency limit from an execution's latency and error
+func (c *ConMan[T]) adapt(latency time.Duration, err error) {
+	a := c.opts.adaptive
+	if a == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+	c.withLock(func() {
+		limit := a.algorithm.Update(c.limiter.limit(), latency, err)
+		c.limiter.resize(min(max(limit, a.min), a.max))
+	})
+}
+
+// record stores the result of a task and sends it to the stream, if any.
+// In fail-fast mode, the first failure cancels all the other tasks, unless
+// the task was cancelled on its own or by Shutdown, or never ran, such as
+// a dropped task.
+func (c *ConMan[T]) record(r Result[T], cancelled bool) {
+	var stream chan Result[T]
+	c.withLock(func() {
+		stream = c.stream
+		if c.opts.failFast && r.Err != nil && r.Attempts > 0 && !cancelled && c.failure == nil &&
+			!errors.Is(context.Cause(c.ctx), ErrClosed) {
+			c.failure = r.Err
+			c.cancel(r.Err)
+		}
+		if c.opts.noAccumulation {
+			return
+		}
+		if r.Err == nil {
+			c.outputs = append(c.outputs, r.Value)
+		} else {
+			c.errors = append(c.errors, r.Err)
+		}
+		c.results = append(c.results, r)
+		if c.opts.ordered {
+			c.slots[r.Seq] = r
+		}
+	})
+	if stream != nil {
+		stream <- r
+	}
+}
+
+// waitForNextAttempt waits for the given delay before the next retry attempt
+func (c *ConMan[T]) waitForNextAttempt(ctx context.Context, delay time.Duration) error {
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -240,30 +809,46 @@ func (c *ConMan[T]) waitForNextAttempt(ctx context.Context, attempt int, config
 	}
 }
 
-// retry attempts to execute a task up to maxRetries times
-func (c *ConMan[T]) retry(ctx context.Context, t Task[T], config *RetryConfig) {
-	if config == nil {
-		return
-	}
-	var err error
-	for attempts := range config.MaxAttempts {
-		if err = c.waitForNextAttempt(ctx, attempts, config); err != nil {
+// retry attempts to execute a task again, first being the error of its first
+// execution. The error of each attempt is classified again: the task is retried
+// according to the RetryConfig of the last *RetriableError, and retries stop as
+// soon as an attempt succeeds or fails with an error that isn't retriable.
+// The retry hint of the last error, if any, replaces the computed delay. If the
+// next attempt would start after the MaxElapsed budget counted from start, or
+// if the retry budget is spent, it gives up with a *RetryError instead.
+// It returns the output and error of the last attempt, along with the number
+// of executions performed.
+func (c *ConMan[T]) retry(ctx context.Context, t Task[T], first *RetriableError, start time.Time) (op T, executions int, err error) {
+	err = first
+	for {
+		re, ok := err.(*RetriableError)
+		if !ok || re.RetryConfig == nil || executions >= re.RetryConfig.MaxAttempts {
+			break
+		}
+		config := re.RetryConfig
+		delay := config.backoff().Next(executions)
+		if hint, ok := retryHint(err); ok {
+			delay = hint
+		}
+		budget := time.Duration(config.MaxElapsed) * time.Millisecond
+		if budget > 0 && time.Since(start)+delay > budget {
+			err = &RetryError{Attempts: executions + 1, Err: err, Reason: ErrMaxElapsed}
+			break
+		}
+		if c.budget != nil && !c.budget.tryRetry() {
+			err = &RetryError{Attempts: executions + 1, Err: err, Reason: ErrRetryBudgetExhausted}
+			break
+		}
+		if err = c.waitForNextAttempt(ctx, delay); err != nil {
 			break
 		}
-		var opp T
-		opp, err = t.Execute(ctx)
+		executions++
+		op, err = c.execute(ctx, t, executions+1)
 		if err == nil {
-			c.withLock(func() {
-				c.outputs = append(c.outputs, opp)
-			})
-			return
+			break
 		}
 	}
-	if err != nil {
-		c.withLock(func() {
-			c.errors = append(c.errors, err)
-		})
-	}
+	return op, executions, err
 }
 
 // withLock executes a function while holding the mutex lock for thread safety
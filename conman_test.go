@@ -5,9 +5,11 @@ package conman
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -71,6 +73,58 @@ func (d *slowdoubler) Execute(ctx context.Context) (int, error) {
 	}
 }
 
+type sleepydoubler struct {
+	delay   time.Duration
+	operand int
+}
+
+func (d *sleepydoubler) Execute(ctx context.Context) (int, error) {
+	select {
+	case <-ctx.Done():
+		return -1, ctx.Err()
+	case <-time.After(d.delay):
+		return d.operand * 2, nil
+	}
+}
+
+// gauge tracks the number of tasks running concurrently
+type gauge struct {
+	mu      sync.Mutex
+	current int
+	peak    int
+}
+
+func (g *gauge) enter() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.current++
+	g.peak = max(g.peak, g.current)
+}
+
+func (g *gauge) leave() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.current--
+}
+
+func (g *gauge) max() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.peak
+}
+
+type gaugedtask struct {
+	gauge *gauge
+	delay time.Duration
+}
+
+func (g *gaugedtask) Execute(ctx context.Context) (int, error) {
+	g.gauge.enter()
+	defer g.gauge.leave()
+	time.Sleep(g.delay)
+	return 0, nil
+}
+
 func TestCaptureOutputs(t *testing.T) {
 	t.Parallel()
 	ctx := t.Context()
@@ -143,6 +197,178 @@ func TestConcurrencyLimit(t *testing.T) {
 	}
 }
 
+func TestSetLimit(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	if err := cm.SetLimit(1); err == nil || err.Error() != "limit must be at least 2, got 1" {
+		t.Errorf("Expected an error for a limit of 1, got %v", err)
+	}
+	if err := cm.SetLimit(4); err != nil {
+		t.Fatalf("SetLimit returned an unexpected error: %v", err)
+	}
+	if cm.Limit() != 4 {
+		t.Errorf("Expected limit 4, got %d", cm.Limit())
+	}
+
+	g := &gauge{}
+	for range 8 {
+		cm.Run(ctx, &gaugedtask{gauge: g, delay: 50 * time.Millisecond})
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if g.max() != 4 {
+		t.Errorf("Expected 4 tasks to run concurrently, got %d", g.max())
+	}
+}
+
+func TestShrinkLimit(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	before := &gauge{}
+	for range 4 {
+		cm.Run(ctx, &gaugedtask{gauge: before, delay: 50 * time.Millisecond})
+	}
+	if err := cm.SetLimit(2); err != nil {
+		t.Fatalf("SetLimit returned an unexpected error: %v", err)
+	}
+	after := &gauge{}
+	for range 4 {
+		cm.Run(ctx, &gaugedtask{gauge: after, delay: 10 * time.Millisecond})
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if before.max() != 4 {
+		t.Errorf("Expected running tasks to finish, got %d concurrent tasks", before.max())
+	}
+	if after.max() > 2 {
+		t.Errorf("Expected at most 2 concurrent tasks after shrinking, got %d", after.max())
+	}
+}
+
+type prioritytask struct {
+	priority int
+	mu       *sync.Mutex
+	started  *[]int
+}
+
+func (p *prioritytask) Priority() int {
+	return p.priority
+}
+
+func (p *prioritytask) Execute(ctx context.Context) (int, error) {
+	p.mu.Lock()
+	*p.started = append(*p.started, p.priority)
+	p.mu.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	return p.priority, nil
+}
+
+func TestPriorityScheduling(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithPriorityScheduling(0))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &sleepydoubler{operand: 1, delay: 50 * time.Millisecond})
+	cm.Run(ctx, &sleepydoubler{operand: 2, delay: 500 * time.Millisecond})
+
+	var mu sync.Mutex
+	var started []int
+	var wg sync.WaitGroup
+	for _, p := range []int{1, 3, 2} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			cm.Run(ctx, &prioritytask{priority: p, mu: &mu, started: &started})
+		}()
+		time.Sleep(10 * time.Millisecond) // Ensure tasks are queued in order
+	}
+	wg.Wait()
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+
+	if !slices.Equal(started, []int{3, 2, 1}) {
+		t.Errorf("Expected tasks to start by decreasing priority, got %v", started)
+	}
+}
+
+type weightedtask struct {
+	gaugedtask
+	weight int64
+}
+
+func (w *weightedtask) Weight() int64 {
+	return w.weight
+}
+
+func TestWeightedTasks(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	g := &gauge{}
+	for range 4 {
+		cm.Run(ctx, &weightedtask{gaugedtask: gaugedtask{gauge: g, delay: 30 * time.Millisecond}, weight: 2})
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if g.max() != 2 {
+		t.Errorf("Expected 2 tasks of weight 2 to run concurrently, got %d", g.max())
+	}
+
+	if err := cm.Run(ctx, &weightedtask{weight: 0}); err == nil || err.Error() != "task weight must be at least 1, got 0" {
+		t.Errorf("Expected an error for a weight of 0, got %v", err)
+	}
+}
+
+func TestWeightAboveLimit(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	if err := cm.SetLimit(2); err != nil {
+		t.Fatalf("SetLimit returned an unexpected error: %v", err)
+	}
+
+	g := &gauge{}
+	for _, weight := range []int64{1, 3, 1} {
+		task := &weightedtask{gaugedtask: gaugedtask{gauge: g, delay: 20 * time.Millisecond}, weight: weight}
+		if err := cm.Run(ctx, task); err != nil {
+			t.Fatalf("Expected a weight above the current limit to be accepted, got %v", err)
+		}
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if len(cm.Outputs()) != 3 {
+		t.Errorf("Expected 3 outputs, got %v", cm.Errors())
+	}
+	if g.max() != 1 {
+		t.Errorf("Expected the task above the limit to run alone, got %d concurrent tasks", g.max())
+	}
+}
+
 func TestRetries(t *testing.T) {
 	t.Parallel()
 	ctx := t.Context()
@@ -191,6 +417,149 @@ func TestMaxRetries(t *testing.T) {
 	}
 }
 
+// stubborndoubler always fails with the given retry config
+type stubborndoubler struct {
+	config *RetryConfig
+}
+
+func (s *stubborndoubler) Execute(ctx context.Context) (int, error) {
+	return -1, &RetriableError{Err: errBoom, RetryConfig: s.config}
+}
+
+var errBoom = errors.New("boom")
+
+func TestMaxElapsed(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	start := time.Now()
+	cm.Run(ctx, &stubborndoubler{config: &RetryConfig{
+		MaxAttempts:   100,
+		InitialDelay:  20,
+		BackoffFactor: 1.0,
+		MaxDelay:      20,
+		MaxElapsed:    70,
+	}})
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected retries to stop within MaxElapsed, took %v", elapsed)
+	}
+
+	r := cm.Results()[0]
+	var re *RetryError
+	if !errors.As(r.Err, &re) {
+		t.Fatalf("Expected a *RetryError, got %T: %v", r.Err, r.Err)
+	}
+	if !errors.Is(r.Err, ErrMaxElapsed) || !errors.Is(r.Err, errBoom) {
+		t.Errorf("Expected the error to wrap ErrMaxElapsed and the last failure, got %v", r.Err)
+	}
+	if re.Attempts != r.Attempts || r.Attempts < 2 || r.Attempts > 5 {
+		t.Errorf("Expected a few attempts within 70ms, got %d (result says %d)", re.Attempts, r.Attempts)
+	}
+}
+
+// throttleddoubler fails once with a retry hint, then succeeds
+type throttleddoubler struct {
+	operand int
+	hint    RetriableError
+	failed  bool
+}
+
+func (d *throttleddoubler) Execute(ctx context.Context) (int, error) {
+	if !d.failed {
+		d.failed = true
+		err := d.hint
+		err.Err = errors.New("throttled")
+		err.RetryConfig = &RetryConfig{MaxAttempts: 2, InitialDelay: 5000, BackoffFactor: 1.0, MaxDelay: 5000}
+		return -1, &err
+	}
+	return d.operand * 2, nil
+}
+
+func TestRetryHints(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	start := time.Now()
+	cm.Run(ctx, &throttleddoubler{operand: 1, hint: RetriableError{RetryAfter: 20 * time.Millisecond}})
+	cm.Run(ctx, &throttleddoubler{operand: 2, hint: RetriableError{RetryAt: time.Now().Add(40 * time.Millisecond)}})
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	elapsed := time.Since(start)
+	if elapsed < 40*time.Millisecond || elapsed > time.Second {
+		t.Errorf("Expected the retry hints to replace the 5s delay, took %v", elapsed)
+	}
+	if len(cm.Outputs()) != 2 {
+		t.Errorf("Expected both tasks to succeed on retry, got %v", cm.Errors())
+	}
+}
+
+// scriptedtask fails with the given errors in turn, then succeeds.
+// It records the attempt number of each execution.
+type scriptedtask struct {
+	errs     []error
+	attempts []int
+}
+
+func (s *scriptedtask) Execute(ctx context.Context) (int, error) {
+	s.attempts = append(s.attempts, Attempt(ctx))
+	if i := len(s.attempts) - 1; i < len(s.errs) {
+		return -1, s.errs[i]
+	}
+	return 42, nil
+}
+
+func TestRetryReclassification(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	permanent := &scriptedtask{errs: []error{
+		(&RetriableError{Err: errors.New("unavailable")}).WithNoBackoff(),
+		errBoom,
+		(&RetriableError{Err: errors.New("unavailable")}).WithNoBackoff(),
+	}}
+	escalated := &scriptedtask{errs: []error{
+		&RetriableError{Err: errors.New("unavailable"), RetryConfig: &RetryConfig{MaxAttempts: 1}},
+		&RetriableError{Err: errors.New("throttled"), RetryConfig: &RetryConfig{MaxAttempts: 3}},
+	}}
+	cm.Run(ctx, permanent)
+	cm.Run(ctx, escalated)
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+
+	for _, r := range cm.Results() {
+		switch r.Task {
+		case permanent:
+			if r.Err != errBoom || r.Attempts != 2 {
+				t.Errorf("Expected retries to stop at the permanent failure, got %+v", r)
+			}
+		case escalated:
+			if r.Err != nil || r.Attempts != 3 {
+				t.Errorf("Expected the second retry config to allow a third attempt, got %+v", r)
+			}
+		}
+	}
+	if !slices.Equal(escalated.attempts, []int{1, 2, 3}) {
+		t.Errorf("Expected the attempt numbers in the task context, got %v", escalated.attempts)
+	}
+}
+
 func TestDispatchTimeout(t *testing.T) {
 	t.Parallel()
 	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
@@ -217,61 +586,577 @@ func TestDispatchTimeout(t *testing.T) {
 	}
 }
 
-func TestContextPropagation(t *testing.T) {
+func TestDispatchTimeoutWhileBlocked(t *testing.T) {
 	t.Parallel()
-	ctx, cancel := context.WithCancel(context.Background())
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
 
-	cm, err := New[int](3)
+	cm.Run(ctx, &sleepydoubler{operand: 299, delay: 5 * time.Second})
+	cm.Run(ctx, &sleepydoubler{operand: 532, delay: 5 * time.Second})
+
+	dispatchCtx, dispatchCancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer dispatchCancel()
+	start := time.Now()
+	err = cm.Run(dispatchCtx, &doubler{operand: 203})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected context deadline exceeded error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected Run to give up at the dispatch deadline, waited %v", elapsed)
+	}
+
+	cancel()
+	if err := cm.Wait(t.Context()); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if len(cm.Results()) != 2 {
+		t.Errorf("Expected only the 2 dispatched tasks to have results, got %d", len(cm.Results()))
+	}
+}
+
+func TestTryRun(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
 	if err != nil {
 		t.Fatalf("Failed to create ConMan: %v", err)
 	}
-	cm.Run(ctx, &slowdoubler{operand: 299, delayInMiliseconds: 100})
-	cm.Run(ctx, &slowdoubler{operand: 532, delayInMiliseconds: 200})
-	cm.Run(ctx, &slowdoubler{operand: 203, delayInMiliseconds: 400})
 
-	go func() {
-		<-time.After(300 * time.Millisecond)
-		cancel()
-	}()
+	if err := cm.TryRun(ctx, &slowdoubler{operand: 299}); err != nil {
+		t.Fatalf("TryRun returned an unexpected error: %v", err)
+	}
+	if err := cm.TryRun(ctx, &slowdoubler{operand: 532}); err != nil {
+		t.Fatalf("TryRun returned an unexpected error: %v", err)
+	}
+	if err := cm.TryRun(ctx, &doubler{operand: 203}); !errors.Is(err, ErrAtCapacity) {
+		t.Errorf("Expected ErrAtCapacity, got %v", err)
+	}
 
-	if err := cm.Wait(context.Background()); err != nil {
+	if err := cm.Wait(ctx); err != nil {
 		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
 	}
-	for _, o := range []int{598, 1064} {
-		if !slices.Contains(cm.Outputs(), o) {
-			t.Errorf("Expected output %v is not part of the captured outputs", o)
-		}
+	if err := cm.TryRun(ctx, &doubler{operand: 203}); err != nil {
+		t.Errorf("Expected TryRun to succeed once slots are free, got %v", err)
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if !slices.Contains(cm.Outputs(), 406) {
+		t.Errorf("Expected output %v is not part of the captured outputs", 406)
 	}
+}
 
-	if slices.Contains(cm.Outputs(), 406) {
-		t.Errorf("Didn't expect output %v in the captured outputs", 406)
+func TestClose(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
 	}
-	if len(cm.Errors()) < 1 {
-		t.Error("Expected one error, got none")
+
+	cm.Run(ctx, &slowdoubler{operand: 299})
+	if err := cm.Close(); err != nil {
+		t.Fatalf("Close returned an unexpected error: %v", err)
 	}
-	errMsg := cm.Errors()[0].Error()
-	if errMsg != "context canceled" {
-		t.Errorf("Expected a 'context canceled' error, got '%s'", errMsg)
+	if err := cm.Close(); err != nil {
+		t.Fatalf("Close returned an unexpected error on second call: %v", err)
+	}
+	if err := cm.Run(ctx, &doubler{operand: 532}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from Run, got %v", err)
+	}
+	if _, err := cm.Submit(ctx, &doubler{operand: 532}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Expected ErrClosed from Submit, got %v", err)
+	}
+
+	select {
+	case <-cm.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected Done to be closed once the running task completes")
+	}
+	if !slices.Equal(cm.Outputs(), []int{598}) {
+		t.Errorf("Expected the running task to complete, got outputs %v", cm.Outputs())
 	}
 }
 
-func TestNewValidation(t *testing.T) {
+func TestShutdown(t *testing.T) {
 	t.Parallel()
-	tests := []struct {
-		name             string
-		concurrencyLimit int64
-		expectError      bool
-		errorContains    string
-	}{
-		{
-			name:             "Valid concurrency limit 2",
-			concurrencyLimit: 2,
-			expectError:      false,
-		},
-		{
-			name:             "Valid concurrency limit 5",
-			concurrencyLimit: 5,
-			expectError:      false,
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &slowdoubler{operand: 299})
+	cm.Run(ctx, &slowdoubler{operand: 532})
+	shutdownCtx, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+	if err := cm.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown returned an unexpected error: %v", err)
+	}
+	if len(cm.Outputs()) != 2 {
+		t.Errorf("Expected running tasks to be drained, got outputs %v", cm.Outputs())
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &sleepydoubler{operand: 299, delay: 5 * time.Second})
+	cm.Run(ctx, &slowdoubler{operand: 532, delayInMiliseconds: 10})
+	blocked := make(chan error)
+	go func() {
+		blocked <- cm.Run(ctx, &doubler{operand: 203})
+	}()
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	time.Sleep(50 * time.Millisecond) // Let the blocked task get the freed slot
+	if err := cm.Shutdown(shutdownCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline exceeded error, got %v", err)
+	}
+	if err := <-blocked; err != nil {
+		t.Errorf("Expected the blocked task to be dispatched before shutdown, got %v", err)
+	}
+
+	select {
+	case <-cm.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected Done to be closed once the cancelled task returns")
+	}
+	if !containsError(cm.Errors(), context.Canceled) {
+		t.Errorf("Expected the long running task to be cancelled, got %v", cm.Errors())
+	}
+	if len(cm.Outputs()) != 2 {
+		t.Errorf("Expected 2 outputs, got %v", cm.Outputs())
+	}
+}
+
+func TestContextPropagation(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cm, err := New[int](3)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	cm.Run(ctx, &slowdoubler{operand: 299, delayInMiliseconds: 100})
+	cm.Run(ctx, &slowdoubler{operand: 532, delayInMiliseconds: 200})
+	cm.Run(ctx, &slowdoubler{operand: 203, delayInMiliseconds: 400})
+
+	go func() {
+		<-time.After(300 * time.Millisecond)
+		cancel()
+	}()
+
+	if err := cm.Wait(context.Background()); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	for _, o := range []int{598, 1064} {
+		if !slices.Contains(cm.Outputs(), o) {
+			t.Errorf("Expected output %v is not part of the captured outputs", o)
+		}
+	}
+
+	if slices.Contains(cm.Outputs(), 406) {
+		t.Errorf("Didn't expect output %v in the captured outputs", 406)
+	}
+	if len(cm.Errors()) < 1 {
+		t.Error("Expected one error, got none")
+	}
+	errMsg := cm.Errors()[0].Error()
+	if errMsg != "context canceled" {
+		t.Errorf("Expected a 'context canceled' error, got '%s'", errMsg)
+	}
+}
+
+func TestOrderedResults(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](5, WithOrderedResults())
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &slowdoubler{operand: 299, delayInMiliseconds: 60})
+	cm.Run(ctx, &errdoubler{operand: 532})
+	cm.Run(ctx, &slowdoubler{operand: 203, delayInMiliseconds: 30})
+	cm.Run(ctx, &doubler{operand: 17})
+
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+
+	results := cm.OrderedResults()
+	if len(results) != 4 {
+		t.Fatalf("Expected 4 results, got %d", len(results))
+	}
+	for i, want := range []int{598, -1, 406, 34} {
+		r := results[i]
+		if r.Seq != i {
+			t.Errorf("Expected sequence number %d at index %d, got %d", i, i, r.Seq)
+		}
+		if want == -1 {
+			if !r.Failed() {
+				t.Errorf("Expected result at index %d to be marked as failed", i)
+			}
+			continue
+		}
+		if r.Failed() || r.Value != want {
+			t.Errorf("Expected output %d at index %d, got %d (error: %v)", want, i, r.Value, r.Err)
+		}
+	}
+}
+
+func TestResults(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](5)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	ok := &doubler{operand: 299}
+	ko := &errdoubler{operand: 532}
+	flaky := &flakydoubler{operand: 203}
+	cm.Run(ctx, ok)
+	cm.Run(ctx, ko)
+	cm.Run(ctx, flaky)
+
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+
+	results := cm.Results()
+	if len(results) != 3 {
+		t.Fatalf("Expected 3 results, got %d", len(results))
+	}
+	for _, r := range results {
+		if r.End.Before(r.Start) || r.Duration() < 0 {
+			t.Errorf("Expected end time after start time, got %v and %v", r.Start, r.End)
+		}
+		switch r.Task {
+		case ok:
+			if r.Failed() || r.Value != 598 || r.Attempts != 1 {
+				t.Errorf("Unexpected result for successful task: %+v", r)
+			}
+		case ko:
+			if !r.Failed() || r.Err.Error() != "Error calculating for 532" || r.Attempts != 1 {
+				t.Errorf("Unexpected result for failing task: %+v", r)
+			}
+		case flaky:
+			if r.Failed() || r.Value != 406 || r.Attempts != 3 {
+				t.Errorf("Unexpected result for flaky task: %+v", r)
+			}
+		default:
+			t.Errorf("Unexpected task in results: %v", r.Task)
+		}
+	}
+}
+
+func TestOrderedResultsDisabled(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &doubler{operand: 299})
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if results := cm.OrderedResults(); results != nil {
+		t.Errorf("Expected no ordered results outside of ordered mode, got %v", results)
+	}
+}
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithoutAccumulation())
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	results := cm.Stream()
+	go func() {
+		for i := range 10 {
+			cm.Run(ctx, &doubler{operand: i})
+		}
+		cm.Run(ctx, &errdoubler{operand: 10})
+		cm.Wait(ctx)
+	}()
+
+	sum, failures := 0, 0
+	for r := range results {
+		if r.Failed() {
+			failures++
+			continue
+		}
+		sum += r.Value
+	}
+	if sum != 90 {
+		t.Errorf("Expected the streamed outputs to sum up to 90, got %d", sum)
+	}
+	if failures != 1 {
+		t.Errorf("Expected 1 streamed failure, got %d", failures)
+	}
+	if len(cm.Outputs()) != 0 || len(cm.Errors()) != 0 || len(cm.Results()) != 0 {
+		t.Errorf("Expected no accumulated results, got %d outputs, %d errors and %d results",
+			len(cm.Outputs()), len(cm.Errors()), len(cm.Results()))
+	}
+}
+
+func TestStreamWithAccumulation(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	results := cm.Stream()
+	go func() {
+		cm.Run(ctx, &doubler{operand: 299})
+		cm.Run(ctx, &doubler{operand: 532})
+		cm.Wait(ctx)
+	}()
+
+	count := 0
+	for range results {
+		count++
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 streamed results, got %d", count)
+	}
+	if len(cm.Outputs()) != 2 {
+		t.Errorf("Expected 2 accumulated outputs, got %d", len(cm.Outputs()))
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](3, WithFailFast())
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	start := time.Now()
+	cm.Run(ctx, &sleepydoubler{operand: 299, delay: 5 * time.Second})
+	cm.Run(ctx, &errdoubler{operand: 532})
+
+	err = cm.Wait(ctx)
+	if err == nil || err.Error() != "Error calculating for 532" {
+		t.Errorf("Expected Wait to return the first task error, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the running task to be cancelled, waited %v", elapsed)
+	}
+	if !containsError(cm.Errors(), context.Canceled) {
+		t.Errorf("Expected the running task to report a cancellation, got %v", cm.Errors())
+	}
+
+	err = cm.Run(ctx, &doubler{operand: 203})
+	if !errors.Is(err, ErrAborted) {
+		t.Errorf("Expected Run to be rejected with ErrAborted, got %v", err)
+	}
+}
+
+func TestFailFastIgnoresCancelledTask(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](3, WithFailFast())
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	h, err := cm.Submit(ctx, &sleepydoubler{operand: 1, delay: 5 * time.Second})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	sibling, err := cm.Submit(ctx, &sleepydoubler{operand: 2, delay: 50 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	h.Cancel()
+	if _, err := h.Await(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the cancelled task to report a cancellation, got %v", err)
+	}
+
+	if v, err := sibling.Await(ctx); err != nil || v != 4 {
+		t.Errorf("Expected the sibling task to complete, got %v, %v", v, err)
+	}
+	if err := cm.Run(ctx, &doubler{operand: 3}); err != nil {
+		t.Errorf("Expected Run to still accept tasks, got %v", err)
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Errorf("Expected Wait not to report the cancellation as a failure, got %v", err)
+	}
+}
+
+func TestFailFastIgnoresShutdown(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithFailFast())
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &sleepydoubler{operand: 1, delay: 5 * time.Second})
+	shutdownCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := cm.Shutdown(shutdownCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Shutdown to time out, got %v", err)
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Errorf("Expected Wait not to report the shutdown as a task failure, got %v", err)
+	}
+	if !containsError(cm.Errors(), context.Canceled) {
+		t.Errorf("Expected the running task to report a cancellation, got %v", cm.Errors())
+	}
+}
+
+func TestWithoutFailFast(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](3)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &errdoubler{operand: 532})
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if err := cm.Run(ctx, &doubler{operand: 203}); err != nil {
+		t.Errorf("Expected Run to accept tasks after a failure, got %v", err)
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if !slices.Contains(cm.Outputs(), 406) {
+		t.Errorf("Expected output %v is not part of the captured outputs", 406)
+	}
+}
+
+type panickydoubler struct {
+	operand  int
+	runCount int
+}
+
+func (p *panickydoubler) Execute(ctx context.Context) (int, error) {
+	if p.runCount < 1 {
+		p.runCount++
+		panic(fmt.Sprintf("cannot double %d", p.operand))
+	}
+	return p.operand * 2, nil
+}
+
+func TestPanicRecovery(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &panickydoubler{operand: 299})
+	cm.Run(ctx, &doubler{operand: 532})
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+
+	if !slices.Contains(cm.Outputs(), 1064) {
+		t.Errorf("Expected output %v is not part of the captured outputs", 1064)
+	}
+	if len(cm.Errors()) != 1 {
+		t.Fatalf("Expected 1 error, got %d", len(cm.Errors()))
+	}
+	var pe *PanicError
+	if !errors.As(cm.Errors()[0], &pe) {
+		t.Fatalf("Expected a *PanicError, got %T", cm.Errors()[0])
+	}
+	if pe.Value != "cannot double 299" {
+		t.Errorf("Expected panic value 'cannot double 299', got %v", pe.Value)
+	}
+	if !strings.Contains(string(pe.Stack), "panickydoubler") {
+		t.Errorf("Expected the stack trace to mention the panicking task, got %s", pe.Stack)
+	}
+}
+
+func TestRetriablePanics(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithRetriablePanics(&RetryConfig{MaxAttempts: 2}))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &panickydoubler{operand: 299})
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+
+	if len(cm.Errors()) != 0 {
+		t.Errorf("Expected no errors, got %v", cm.Errors())
+	}
+	results := cm.Results()
+	if len(results) != 1 || results[0].Value != 598 || results[0].Attempts != 2 {
+		t.Errorf("Expected the task to succeed on its second attempt, got %+v", results)
+	}
+}
+
+type unconfiguredretrier struct{}
+
+func (u *unconfiguredretrier) Execute(ctx context.Context) (int, error) {
+	return -1, &RetriableError{Err: fmt.Errorf("No retry config")}
+}
+
+func TestRetriableErrorWithoutConfig(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &unconfiguredretrier{})
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if !containsError(cm.Errors(), fmt.Errorf("No retry config")) {
+		t.Errorf("Expected the error to be recorded, got %v", cm.Errors())
+	}
+}
+
+func TestNewValidation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name             string
+		concurrencyLimit int64
+		options          []Option
+		expectError      bool
+		errorContains    string
+	}{
+		{
+			name:             "Valid concurrency limit 2",
+			concurrencyLimit: 2,
+			expectError:      false,
+		},
+		{
+			name:             "Valid concurrency limit 5",
+			concurrencyLimit: 5,
+			expectError:      false,
 		},
 		{
 			name:             "Invalid concurrency limit 1",
@@ -285,17 +1170,213 @@ func TestNewValidation(t *testing.T) {
 			expectError:      true,
 			errorContains:    "concurrencyLimit must be at least 2, got 0",
 		},
+		{
+			name:             "Incompatible options",
+			concurrencyLimit: 2,
+			options:          []Option{WithOrderedResults(), WithoutAccumulation()},
+			expectError:      true,
+			errorContains:    "WithOrderedResults cannot be combined with WithoutAccumulation",
+		},
+		{
+			name:             "Invalid panic retry config",
+			concurrencyLimit: 2,
+			options:          []Option{WithRetriablePanics(&RetryConfig{MaxAttempts: 0})},
+			expectError:      true,
+			errorContains:    "invalid panic retry config: MaxAttempts must be positive, got 0",
+		},
 		{
 			name:             "Invalid concurrency limit negative",
 			concurrencyLimit: -5,
 			expectError:      true,
 			errorContains:    "concurrencyLimit must be at least 2, got -5",
 		},
+		{
+			name:             "Nil adaptive limit algorithm",
+			concurrencyLimit: 4,
+			options:          []Option{WithAdaptiveLimit(2, 6, nil)},
+			expectError:      true,
+			errorContains:    "adaptive limit algorithm cannot be nil",
+		},
+		{
+			name:             "Invalid adaptive limit minimum",
+			concurrencyLimit: 4,
+			options:          []Option{WithAdaptiveLimit(0, 6, &AIMD{})},
+			expectError:      true,
+			errorContains:    "adaptive limit minimum must be at least 2, got 0",
+		},
+		{
+			name:             "Adaptive limit minimum below 2",
+			concurrencyLimit: 4,
+			options:          []Option{WithAdaptiveLimit(1, 6, &AIMD{})},
+			expectError:      true,
+			errorContains:    "adaptive limit minimum must be at least 2, got 1",
+		},
+		{
+			name:             "Adaptive limit maximum below minimum",
+			concurrencyLimit: 4,
+			options:          []Option{WithAdaptiveLimit(6, 2, &AIMD{})},
+			expectError:      true,
+			errorContains:    "adaptive limit maximum (2) cannot be less than minimum (6)",
+		},
+		{
+			name:             "Invalid queue depth",
+			concurrencyLimit: 2,
+			options:          []Option{WithQueue(0, OverflowBlock)},
+			expectError:      true,
+			errorContains:    "queue depth must be at least 1, got 0",
+		},
+		{
+			name:             "Invalid overflow policy",
+			concurrencyLimit: 2,
+			options:          []Option{WithQueue(1, OverflowPolicy(42))},
+			expectError:      true,
+			errorContains:    "invalid overflow policy 42",
+		},
+		{
+			name:             "Invalid worker pool size",
+			concurrencyLimit: 2,
+			options:          []Option{WithWorkerPool(0)},
+			expectError:      true,
+			errorContains:    "worker pool size must be at least 1, got 0",
+		},
+		{
+			name:             "Worker init without worker pool",
+			concurrencyLimit: 2,
+			options:          []Option{WithWorkerInit(func(ctx context.Context, worker int) (any, error) { return nil, nil }, nil)},
+			expectError:      true,
+			errorContains:    "WithWorkerInit requires WithWorkerPool",
+		},
+		{
+			name:             "Nil worker init function",
+			concurrencyLimit: 2,
+			options:          []Option{WithWorkerPool(2), WithWorkerInit(nil, nil)},
+			expectError:      true,
+			errorContains:    "worker init function cannot be nil",
+		},
+		{
+			name:             "Negative task timeout",
+			concurrencyLimit: 2,
+			options:          []Option{WithTaskTimeout(-time.Second)},
+			expectError:      true,
+			errorContains:    "task timeout cannot be negative, got -1s",
+		},
+		{
+			name:             "Invalid timeout retry config",
+			concurrencyLimit: 2,
+			options:          []Option{WithTimeoutRetry(&RetryConfig{MaxAttempts: 0})},
+			expectError:      true,
+			errorContains:    "invalid timeout retry config: MaxAttempts must be positive, got 0",
+		},
+		{
+			name:             "Retry policy without matchers",
+			concurrencyLimit: 2,
+			options:          []Option{WithRetryPolicy(&RetryConfig{MaxAttempts: 1})},
+			expectError:      true,
+			errorContains:    "retry policy requires at least one matcher",
+		},
+		{
+			name:             "Nil retry policy config",
+			concurrencyLimit: 2,
+			options:          []Option{WithRetryPolicy(nil, IsTimeout)},
+			expectError:      true,
+			errorContains:    "retry policy config cannot be nil",
+		},
+		{
+			name:             "Invalid retry policy config",
+			concurrencyLimit: 2,
+			options:          []Option{WithRetryPolicy(&RetryConfig{}, IsTimeout)},
+			expectError:      true,
+			errorContains:    "invalid retry policy config: MaxAttempts must be positive, got 0",
+		},
+		{
+			name:             "Negative retry budget ratio",
+			concurrencyLimit: 2,
+			options:          []Option{WithRetryBudget(-0.1, time.Second, 0)},
+			expectError:      true,
+			errorContains:    "retry budget ratio cannot be negative, got -0.1",
+		},
+		{
+			name:             "Invalid retry budget window",
+			concurrencyLimit: 2,
+			options:          []Option{WithRetryBudget(0.1, 0, 0)},
+			expectError:      true,
+			errorContains:    "retry budget window must be positive, got 0s",
+		},
+		{
+			name:             "Negative retry budget minimum",
+			concurrencyLimit: 2,
+			options:          []Option{WithRetryBudget(0.1, time.Second, -1)},
+			expectError:      true,
+			errorContains:    "retry budget minimum cannot be negative, got -1",
+		},
+		{
+			name:             "Invalid circuit breaker failure threshold",
+			concurrencyLimit: 2,
+			options:          []Option{WithCircuitBreaker(&BreakerConfig{CoolDown: time.Second})},
+			expectError:      true,
+			errorContains:    "invalid circuit breaker config: FailureThreshold must be positive, got 0",
+		},
+		{
+			name:             "Invalid circuit breaker cool-down",
+			concurrencyLimit: 2,
+			options:          []Option{WithCircuitBreaker(&BreakerConfig{FailureThreshold: 1})},
+			expectError:      true,
+			errorContains:    "invalid circuit breaker config: CoolDown must be positive, got 0s",
+		},
+		{
+			name:             "Negative circuit breaker half-open probes",
+			concurrencyLimit: 2,
+			options:          []Option{WithCircuitBreaker(&BreakerConfig{FailureThreshold: 1, CoolDown: time.Second, HalfOpenProbes: -1})},
+			expectError:      true,
+			errorContains:    "invalid circuit breaker config: HalfOpenProbes cannot be negative, got -1",
+		},
+		{
+			name:             "Invalid rate limit tokens",
+			concurrencyLimit: 2,
+			options:          []Option{WithRateLimit(0, time.Second, 1)},
+			expectError:      true,
+			errorContains:    "rate limit tokens must be at least 1, got 0",
+		},
+		{
+			name:             "Rate limit interval too short",
+			concurrencyLimit: 2,
+			options:          []Option{WithRateLimit(10, 5, 1)},
+			expectError:      true,
+			errorContains:    "rate limit interval must be at least 1ns per token, got 5ns for 10 tokens",
+		},
+		{
+			name:             "Invalid rate limit burst",
+			concurrencyLimit: 2,
+			options:          []Option{WithRateLimit(10, time.Second, 0)},
+			expectError:      true,
+			errorContains:    "rate limit burst must be at least 1, got 0",
+		},
+		{
+			name:             "Invalid key concurrency limit",
+			concurrencyLimit: 2,
+			options:          []Option{WithKeyLimits(0, nil)},
+			expectError:      true,
+			errorContains:    "key concurrency limit must be at least 1, got 0",
+		},
+		{
+			name:             "Invalid key limit override",
+			concurrencyLimit: 2,
+			options:          []Option{WithKeyLimits(2, map[string]int64{"a": 0})},
+			expectError:      true,
+			errorContains:    "concurrency limit of key \"a\" must be at least 1, got 0",
+		},
+		{
+			name:             "Negative priority aging",
+			concurrencyLimit: 2,
+			options:          []Option{WithPriorityScheduling(-time.Second)},
+			expectError:      true,
+			errorContains:    "priority aging cannot be negative, got -1s",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cm, err := New[int](tt.concurrencyLimit)
+			cm, err := New[int](tt.concurrencyLimit, tt.options...)
 
 			if tt.expectError {
 				if err == nil {
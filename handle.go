@@ -0,0 +1,137 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+)
+
+// Handle is a reference to a single task dispatched through Submit.
+// It allows waiting for, reading and cancelling that specific task.
+type Handle[T any] struct {
+	done   chan struct{}
+	cancel context.CancelFunc
+	result Result[T]
+}
+
+// newHandle creates a handle whose cancel function is set once the task is dispatched
+func newHandle[T any]() *Handle[T] {
+	return &Handle[T]{
+		done: make(chan struct{}),
+	}
+}
+
+// Done returns a channel that is closed once the task has completed.
+func (h *Handle[T]) Done() <-chan struct{} {
+	return h.done
+}
+
+// Await blocks until the task completes and returns its output and error.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control of the wait itself
+//
+// Returns:
+//   - T: The output of the task
+//   - error: The task execution error, or the context error if ctx is
+//     cancelled before the task completes
+func (h *Handle[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	case <-h.done:
+		return h.result.Value, h.result.Err
+	}
+}
+
+// Cancel cancels the context passed to the task.
+// It has no effect if the task has already completed.
+func (h *Handle[T]) Cancel() {
+	h.cancel()
+}
+
+// Result returns the result of the task without blocking.
+//
+// Returns:
+//   - Result[T]: The task result, zero value if the task is still running
+//   - bool: Whether the task has completed
+func (h *Handle[T]) Result() (Result[T], bool) {
+	select {
+	case <-h.done:
+		return h.result, true
+	default:
+		return Result[T]{}, false
+	}
+}
+
+// complete stores the result of the task and notifies the waiters
+func (h *Handle[T]) complete(r Result[T]) {
+	h.result = r
+	close(h.done)
+}
+
+// AwaitAll blocks until all the given tasks complete.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control of the wait
+//   - handles: Handles of the tasks to wait for
+//
+// Returns:
+//   - []Result[T]: The task results, in the same order as handles
+//   - error: Context cancellation error if ctx is cancelled before all tasks complete
+func AwaitAll[T any](ctx context.Context, handles ...*Handle[T]) ([]Result[T], error) {
+	results := make([]Result[T], len(handles))
+	for i, h := range handles {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-h.done:
+			results[i] = h.result
+		}
+	}
+	return results, nil
+}
+
+// AwaitFirst blocks until the first of the given tasks completes,
+// whether it succeeded or not.
+//
+// Parameters:
+//   - ctx: Context for cancellation and timeout control of the wait
+//   - handles: Handles of the tasks to wait for (at least one)
+//
+// Returns:
+//   - Result[T]: The result of the first task to complete
+//   - error: Context cancellation error if ctx is cancelled before any task completes
+func AwaitFirst[T any](ctx context.Context, handles ...*Handle[T]) (Result[T], error) {
+	if len(handles) == 0 {
+		return Result[T]{}, errors.New("AwaitFirst requires at least one handle")
+	}
+	for _, h := range handles {
+		if r, ok := h.Result(); ok {
+			return r, nil
+		}
+	}
+
+	first := make(chan *Handle[T], len(handles))
+	stop := make(chan struct{})
+	defer close(stop)
+	for _, h := range handles {
+		go func() {
+			select {
+			case <-h.done:
+				first <- h
+			case <-stop:
+			}
+		}()
+	}
+
+	select {
+	case <-ctx.Done():
+		return Result[T]{}, ctx.Err()
+	case h := <-first:
+		return h.result, nil
+	}
+}
@@ -0,0 +1,182 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSubmitAwait(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	h, err := cm.Submit(ctx, &doubler{operand: 299})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	out, err := h.Await(ctx)
+	if err != nil {
+		t.Fatalf("Await returned an unexpected error: %v", err)
+	}
+	if out != 598 {
+		t.Errorf("Expected output 598, got %d", out)
+	}
+	r, ok := h.Result()
+	if !ok {
+		t.Fatal("Expected the result to be available after Await")
+	}
+	if r.Value != 598 || r.Attempts != 1 {
+		t.Errorf("Unexpected result: %+v", r)
+	}
+}
+
+func TestSubmitDispatchError(t *testing.T) {
+	t.Parallel()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	h, err := cm.Submit(ctx, &doubler{operand: 299})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a context canceled error, got %v", err)
+	}
+	if h != nil {
+		t.Errorf("Expected nil handle on dispatch error, got %v", h)
+	}
+}
+
+func TestHandleResultPending(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	h, err := cm.Submit(ctx, &slowdoubler{operand: 299})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	if _, ok := h.Result(); ok {
+		t.Error("Didn't expect the result to be available before the task completes")
+	}
+	select {
+	case <-h.Done():
+		t.Error("Didn't expect the task to be done yet")
+	default:
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := h.Await(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a deadline exceeded error, got %v", err)
+	}
+
+	<-h.Done()
+	if _, ok := h.Result(); !ok {
+		t.Error("Expected the result to be available once done")
+	}
+}
+
+func TestHandleCancel(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cancelled, err := cm.Submit(ctx, &slowdoubler{operand: 299})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	other, err := cm.Submit(ctx, &slowdoubler{operand: 532})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	cancelled.Cancel()
+
+	if _, err := cancelled.Await(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a context canceled error, got %v", err)
+	}
+	if out, err := other.Await(ctx); err != nil || out != 1064 {
+		t.Errorf("Expected the other task to succeed with 1064, got %d (error: %v)", out, err)
+	}
+}
+
+func TestAwaitAll(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](3)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	var handles []*Handle[int]
+	for _, task := range []Task[int]{
+		&slowdoubler{operand: 299, delayInMiliseconds: 50},
+		&errdoubler{operand: 532},
+		&doubler{operand: 203},
+	} {
+		h, err := cm.Submit(ctx, task)
+		if err != nil {
+			t.Fatalf("Submit returned an unexpected error: %v", err)
+		}
+		handles = append(handles, h)
+	}
+
+	results, err := AwaitAll(ctx, handles...)
+	if err != nil {
+		t.Fatalf("AwaitAll returned an unexpected error: %v", err)
+	}
+	if results[0].Value != 598 || results[0].Failed() {
+		t.Errorf("Unexpected first result: %+v", results[0])
+	}
+	if !results[1].Failed() {
+		t.Errorf("Expected second result to be failed: %+v", results[1])
+	}
+	if results[2].Value != 406 || results[2].Failed() {
+		t.Errorf("Unexpected third result: %+v", results[2])
+	}
+}
+
+func TestAwaitFirst(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](3)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	slow, err := cm.Submit(ctx, &slowdoubler{operand: 299, delayInMiliseconds: 300})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	fast, err := cm.Submit(ctx, &slowdoubler{operand: 532, delayInMiliseconds: 10})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+
+	r, err := AwaitFirst(ctx, slow, fast)
+	if err != nil {
+		t.Fatalf("AwaitFirst returned an unexpected error: %v", err)
+	}
+	if r.Value != 1064 {
+		t.Errorf("Expected the fastest task output 1064, got %d", r.Value)
+	}
+
+	if _, err := AwaitFirst[int](ctx); err == nil {
+		t.Error("Expected an error when awaiting no handles")
+	}
+}
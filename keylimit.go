@@ -0,0 +1,150 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// keyLimits enforces a concurrency limit per task limit key (see LimitKeyed), with a limiter per key.
+// Limiters are created on first use and removed once no task holds or waits
+// for them, so that the number of keys doesn't grow without bound.
+type keyLimits struct {
+	mu        sync.Mutex
+	limit     int64
+	overrides map[string]int64
+	aging     time.Duration
+	keys      map[string]*keyLimiter
+}
+
+// keyLimiter is the limiter of a key, along with the number of tasks
+// holding or waiting for it
+type keyLimiter struct {
+	*limiter
+	refs int
+}
+
+// newKeyLimits creates limits allowing limit concurrent tasks per key,
+// or the limit in overrides for the keys it contains
+func newKeyLimits(limit int64, overrides map[string]int64, aging time.Duration) *keyLimits {
+	return &keyLimits{
+		limit:     limit,
+		overrides: overrides,
+		aging:     aging,
+		keys:      make(map[string]*keyLimiter),
+	}
+}
+
+// acquire blocks until a slot of the key is available and takes it.
+// It returns the context error if ctx is done before the slot is taken.
+func (k *keyLimits) acquire(ctx context.Context, key string, priority int) error {
+	if err := k.get(key).acquire(ctx, 1, priority); err != nil {
+		k.put(key)
+		return err
+	}
+	return nil
+}
+
+// tryAcquire takes a slot of the key if one is available right away,
+// and reports whether it did
+func (k *keyLimits) tryAcquire(key string) bool {
+	if !k.get(key).tryAcquire(1) {
+		k.put(key)
+		return false
+	}
+	return true
+}
+
+// release gives back a slot of the key
+func (k *keyLimits) release(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.keys[key].release(1)
+	k.unref(key)
+}
+
+// available reports whether a slot of the key could be taken right away
+func (k *keyLimits) available(key string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	kl, ok := k.keys[key]
+	return !ok || kl.available(1)
+}
+
+// get returns the limiter of a key, creating it if needed, and references it
+func (k *keyLimits) get(key string) *limiter {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	kl, ok := k.keys[key]
+	if !ok {
+		limit, ok := k.overrides[key]
+		if !ok {
+			limit = k.limit
+		}
+		kl = &keyLimiter{limiter: newLimiter(limit, k.aging)}
+		k.keys[key] = kl
+	}
+	kl.refs++
+	return kl.limiter
+}
+
+// put drops a reference to the limiter of a key
+func (k *keyLimits) put(key string) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	k.unref(key)
+}
+
+// unref drops a reference to the limiter of a key, removing the limiter
+// once unused. Must be called with mu held.
+func (k *keyLimits) unref(key string) {
+	kl := k.keys[key]
+	if kl.refs--; kl.refs == 0 {
+		delete(k.keys, key)
+	}
+}
+
+// reserveKey takes a slot of the limit key of a job, if it has one and key limits
+// are enabled. If block is false, it fails with ErrAtCapacity instead of
+// waiting for a slot.
+func (c *ConMan[T]) reserveKey(j *job[T], block bool) error {
+	if c.keys == nil || j.limitKey == "" {
+		return nil
+	}
+	if !block {
+		if !c.keys.tryAcquire(j.limitKey) {
+			return ErrAtCapacity
+		}
+		return nil
+	}
+	return c.keys.acquire(j.ctx, j.limitKey, j.priority)
+}
+
+// releaseKey gives back the slot of the limit key of a job, if it has one, and
+// wakes up the queue so that jobs waiting for that key can be taken
+func (c *ConMan[T]) releaseKey(j *job[T]) {
+	if c.keys == nil || j.limitKey == "" {
+		return
+	}
+	c.keys.release(j.limitKey)
+	if q := c.queue; q != nil {
+		q.mu.Lock()
+		if l, ok := q.lanes[j.limitKey]; ok {
+			c.updateLane(l)
+		}
+		q.ready.Broadcast()
+		q.mu.Unlock()
+	}
+}
+
+// limitKey returns the limit key of a task, empty if it has none or key limits
+// are disabled
+func (c *ConMan[T]) limitKey(t Task[T]) string {
+	if k, ok := t.(LimitKeyed); ok && c.keys != nil {
+		return k.LimitKey()
+	}
+	return ""
+}
@@ -0,0 +1,218 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// tenanttask is a gaugedtask with a limit key
+type tenanttask struct {
+	gaugedtask
+	tenant string
+}
+
+func (t *tenanttask) LimitKey() string {
+	return t.tenant
+}
+
+func TestKeyLimits(t *testing.T) {
+	t.Parallel()
+	modes := map[string][]Option{
+		"spawn": nil,
+		"queue": {WithQueue(100, OverflowBlock)},
+		"pool":  {WithWorkerPool(6), WithQueue(100, OverflowBlock)},
+	}
+	for name, opts := range modes {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+			opts = append(opts, WithKeyLimits(2, map[string]int64{"big": 3}))
+			cm, err := New[int](6, opts...)
+			if err != nil {
+				t.Fatalf("Failed to create ConMan: %v", err)
+			}
+			defer cm.Close()
+
+			all, small, big := &gauge{}, &gauge{}, &gauge{}
+			for range 8 {
+				cm.Run(ctx, &tenanttask{gaugedtask{big, 10 * time.Millisecond}, "big"})
+			}
+			for range 8 {
+				cm.Run(ctx, &tenanttask{gaugedtask{small, 10 * time.Millisecond}, "small"})
+				cm.Run(ctx, &gaugedtask{all, 10 * time.Millisecond})
+			}
+			if err := cm.Wait(ctx); err != nil {
+				t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+			}
+
+			if len(cm.Outputs()) != 24 {
+				t.Errorf("Expected 24 outputs, got %d", len(cm.Outputs()))
+			}
+			if small.max() != 2 || big.max() != 3 {
+				t.Errorf("Expected at most 2 small and 3 big tasks at once, got %d and %d", small.max(), big.max())
+			}
+		})
+	}
+}
+
+func TestKeyLimitersCleanup(t *testing.T) {
+	t.Parallel()
+	k := newKeyLimits(1, nil, 0)
+	if !k.tryAcquire("a") || k.tryAcquire("a") {
+		t.Fatal("Expected a single slot for the key")
+	}
+	if k.available("a") || !k.available("b") {
+		t.Error("Expected only the key at its limit to be unavailable")
+	}
+	k.release("a")
+	if len(k.keys) != 0 {
+		t.Errorf("Expected the limiter to be removed once unused, got %d limiters", len(k.keys))
+	}
+}
+
+func TestKeyLimitsBacklog(t *testing.T) {
+	t.Parallel()
+	modes := map[string][]Option{
+		"queue": {WithQueue(100, OverflowBlock)},
+		"pool":  {WithWorkerPool(4), WithQueue(100, OverflowBlock)},
+	}
+	for name, opts := range modes {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			ctx := t.Context()
+			cm, err := New[int](4, append(opts, WithKeyLimits(1, nil))...)
+			if err != nil {
+				t.Fatalf("Failed to create ConMan: %v", err)
+			}
+			defer cm.Close()
+
+			start := time.Now()
+			for range 5 {
+				cm.Run(ctx, &tenanttask{gaugedtask{&gauge{}, 40 * time.Millisecond}, "busy"})
+			}
+			h, err := cm.Submit(ctx, &tenanttask{gaugedtask{&gauge{}, 0}, "quiet"})
+			if err != nil {
+				t.Fatalf("Submit returned an unexpected error: %v", err)
+			}
+			if _, err := h.Await(ctx); err != nil {
+				t.Fatalf("Await returned an unexpected error: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+				t.Errorf("Expected the quiet tenant not to wait for the busy one, waited %v", elapsed)
+			}
+			if err := cm.Wait(ctx); err != nil {
+				t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+			}
+			if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+				t.Errorf("Expected the busy tenant to run one task at a time, took %v", elapsed)
+			}
+		})
+	}
+}
+
+func TestKeyLimitsQueueLanes(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithQueue(100, OverflowBlock), WithKeyLimits(1, nil))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	for range 3 {
+		cm.Run(ctx, &tenanttask{gaugedtask{&gauge{}, 50 * time.Millisecond}, "busy"})
+	}
+
+	q := cm.queue
+	q.mu.Lock()
+	if q.size != 2 || len(q.lanes) != 1 || q.runnable.Len() != 0 {
+		t.Errorf("Expected 2 jobs in a lane held back by its key, got %d jobs in %d lanes, %d runnable", q.size, len(q.lanes), q.runnable.Len())
+	}
+	q.mu.Unlock()
+
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.lanes) != 0 || q.runnable.Len() != 0 {
+		t.Errorf("Expected the lanes to be removed once empty, got %d lanes, %d runnable", len(q.lanes), q.runnable.Len())
+	}
+}
+
+// tenantcall is a tenanttask calling a downstream service, which may fail
+type tenantcall struct {
+	tenanttask
+	service string
+	fail    bool
+}
+
+func (t *tenantcall) Key() string {
+	return t.service
+}
+
+func (t *tenantcall) Execute(ctx context.Context) (int, error) {
+	t.tenanttask.Execute(ctx)
+	if t.fail {
+		return -1, errBoom
+	}
+	return 1, nil
+}
+
+func TestKeyLimitsWithCircuitBreaker(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4,
+		WithKeyLimits(1, nil),
+		WithCircuitBreaker(&BreakerConfig{FailureThreshold: 2, CoolDown: time.Minute}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	g := &gauge{}
+	for _, tenant := range []string{"acme", "globex"} {
+		task := &tenantcall{tenanttask{gaugedtask{g, 20 * time.Millisecond}, tenant}, "billing", true}
+		if err := cm.Run(ctx, task); err != nil {
+			t.Fatalf("Run returned an unexpected error: %v", err)
+		}
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+
+	if g.max() != 2 {
+		t.Errorf("Expected tasks of different tenants to run concurrently, got %d", g.max())
+	}
+	if s := cm.CircuitState("billing"); s != CircuitOpen {
+		t.Errorf("Expected the failures of both tenants to open the circuit of the service, got %v", s)
+	}
+	if s := cm.CircuitState("acme"); s != CircuitClosed {
+		t.Errorf("Expected no circuit per tenant, got %v", s)
+	}
+}
+
+func TestKeyLimitsTryRun(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4, WithKeyLimits(1, nil))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	if err := cm.TryRun(ctx, &tenanttask{gaugedtask{&gauge{}, 50 * time.Millisecond}, "a"}); err != nil {
+		t.Fatalf("TryRun returned an unexpected error: %v", err)
+	}
+	if err := cm.TryRun(ctx, &tenanttask{gaugedtask{&gauge{}, 0}, "a"}); !errors.Is(err, ErrAtCapacity) {
+		t.Errorf("Expected ErrAtCapacity for a key at its limit, got %v", err)
+	}
+	if err := cm.TryRun(ctx, &tenanttask{gaugedtask{&gauge{}, 0}, "b"}); err != nil {
+		t.Errorf("Expected other keys to be unaffected, got %v", err)
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,131 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"container/heap"
+	"context"
+	"sync"
+	"time"
+)
+
+// limiter is a weighted semaphore whose size can be changed while in use.
+// Waiters are served by decreasing priority, then in FIFO order. A waiter that
+// doesn't fit blocks the ones behind it, so that heavy waiters are not starved.
+type limiter struct {
+	mu      sync.Mutex
+	size    int64
+	used    int64
+	waiters pqueue[*waiter]
+}
+
+// newLimiter creates a limiter allowing size concurrent holders.
+// A non-zero aging raises the priority of waiters by one level per aging period.
+func newLimiter(size int64, aging time.Duration) *limiter {
+	l := &limiter{size: size}
+	l.waiters.sched = newScheduler(aging)
+	return l
+}
+
+// acquire blocks until weight units are available and takes them.
+// It returns the context error if ctx is done before the units are taken.
+func (l *limiter) acquire(ctx context.Context, weight int64, priority int) error {
+	l.mu.Lock()
+	if l.fits(weight) && l.waiters.Len() == 0 {
+		l.used += weight
+		l.mu.Unlock()
+		return nil
+	}
+	w := &waiter{ready: make(chan struct{}), weight: weight}
+	l.waiters.sched.stamp(&w.ticket, priority)
+	heap.Push(&l.waiters, w)
+	l.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if w.index < 0 {
+			// The units were handed over in the meantime, give them back
+			l.used -= weight
+		} else {
+			heap.Remove(&l.waiters, w.index)
+		}
+		// The waiter may have been blocking the ones behind it
+		l.notify()
+		return ctx.Err()
+	}
+}
+
+// tryAcquire takes weight units if they are available right away,
+// and reports whether it did
+func (l *limiter) tryAcquire(weight int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if !l.fits(weight) || l.waiters.Len() > 0 {
+		return false
+	}
+	l.used += weight
+	return true
+}
+
+// available reports whether weight units could be taken right away
+func (l *limiter) available(weight int64) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.fits(weight) && l.waiters.Len() == 0
+}
+
+// release gives back weight units and wakes up the next waiters, if any
+func (l *limiter) release(weight int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.used -= weight
+	l.notify()
+}
+
+// resize changes the number of units. When shrinking, the units in use are
+// kept until released, but no waiter is woken up until usage is below size.
+func (l *limiter) resize(size int64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.size = size
+	l.notify()
+}
+
+// limit returns the current number of units
+func (l *limiter) limit() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.size
+}
+
+// fits reports whether weight units can be taken. A weight larger than
+// the size, which can happen after shrinking, fits if no unit is in use.
+// Must be called with mu held.
+func (l *limiter) fits(weight int64) bool {
+	return l.used+weight <= l.size || l.used == 0
+}
+
+// notify hands free units over to waiters, in order. Must be called with mu held.
+func (l *limiter) notify() {
+	for l.waiters.Len() > 0 {
+		next := l.waiters.items[0]
+		if !l.fits(next.weight) {
+			return
+		}
+		heap.Pop(&l.waiters)
+		l.used += next.weight
+		close(next.ready)
+	}
+}
+
+// waiter is a blocked acquire call
+type waiter struct {
+	ticket
+	ready  chan struct{}
+	weight int64
+}
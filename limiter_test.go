@@ -0,0 +1,212 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// acquired runs fn in a goroutine and returns a channel closed when it returns
+func acquired(fn func()) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		fn()
+		close(done)
+	}()
+	return done
+}
+
+func isDone(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	case <-time.After(20 * time.Millisecond):
+		return false
+	}
+}
+
+func TestLimiterGrow(t *testing.T) {
+	t.Parallel()
+	l := newLimiter(2, 0)
+	l.acquire(context.Background(), 1, 0)
+	l.acquire(context.Background(), 1, 0)
+
+	waiter := acquired(func() { l.acquire(context.Background(), 1, 0) })
+	if isDone(waiter) {
+		t.Fatal("Didn't expect acquire to succeed above the limit")
+	}
+	l.resize(3)
+	if !isDone(waiter) {
+		t.Fatal("Expected acquire to succeed after growing the limit")
+	}
+	if l.limit() != 3 {
+		t.Errorf("Expected limit 3, got %d", l.limit())
+	}
+}
+
+func TestLimiterShrink(t *testing.T) {
+	t.Parallel()
+	l := newLimiter(3, 0)
+	l.acquire(context.Background(), 1, 0)
+	l.acquire(context.Background(), 1, 0)
+	l.acquire(context.Background(), 1, 0)
+	l.resize(2)
+
+	waiter := acquired(func() { l.acquire(context.Background(), 1, 0) })
+	l.release(1)
+	if isDone(waiter) {
+		t.Fatal("Didn't expect acquire to succeed while usage is at the new limit")
+	}
+	l.release(1)
+	if !isDone(waiter) {
+		t.Fatal("Expected acquire to succeed once usage is below the new limit")
+	}
+}
+
+func TestLimiterFIFO(t *testing.T) {
+	t.Parallel()
+	l := newLimiter(1, 0)
+	l.acquire(context.Background(), 1, 0)
+
+	first := acquired(func() { l.acquire(context.Background(), 1, 0) })
+	time.Sleep(10 * time.Millisecond)
+	second := acquired(func() { l.acquire(context.Background(), 1, 0) })
+	time.Sleep(10 * time.Millisecond)
+
+	l.release(1)
+	if !isDone(first) {
+		t.Fatal("Expected the first waiter to be served first")
+	}
+	if isDone(second) {
+		t.Fatal("Didn't expect the second waiter to be served yet")
+	}
+	l.release(1)
+	if !isDone(second) {
+		t.Fatal("Expected the second waiter to be served")
+	}
+}
+
+func TestLimiterPriority(t *testing.T) {
+	t.Parallel()
+	l := newLimiter(1, 0)
+	l.acquire(context.Background(), 1, 0)
+
+	low := acquired(func() { l.acquire(context.Background(), 1, 1) })
+	time.Sleep(10 * time.Millisecond)
+	high := acquired(func() { l.acquire(context.Background(), 1, 5) })
+	time.Sleep(10 * time.Millisecond)
+
+	l.release(1)
+	if !isDone(high) {
+		t.Fatal("Expected the high priority waiter to be served first")
+	}
+	if isDone(low) {
+		t.Fatal("Didn't expect the low priority waiter to be served yet")
+	}
+	l.release(1)
+	if !isDone(low) {
+		t.Fatal("Expected the low priority waiter to be served")
+	}
+}
+
+func TestLimiterAging(t *testing.T) {
+	t.Parallel()
+	l := newLimiter(1, 10*time.Millisecond)
+	l.acquire(context.Background(), 1, 0)
+
+	old := acquired(func() { l.acquire(context.Background(), 1, 0) })
+	time.Sleep(50 * time.Millisecond)
+	recent := acquired(func() { l.acquire(context.Background(), 1, 2) })
+	time.Sleep(10 * time.Millisecond)
+
+	l.release(1)
+	if !isDone(old) {
+		t.Fatal("Expected the aged waiter to be served before the recent higher priority one")
+	}
+	l.release(1)
+	if !isDone(recent) {
+		t.Fatal("Expected the recent waiter to be served")
+	}
+}
+
+func TestLimiterWeights(t *testing.T) {
+	t.Parallel()
+	l := newLimiter(4, 0)
+	l.acquire(context.Background(), 3, 0)
+
+	heavy := acquired(func() { l.acquire(context.Background(), 2, 0) })
+	time.Sleep(10 * time.Millisecond)
+	light := acquired(func() { l.acquire(context.Background(), 1, 0) })
+	if isDone(heavy) {
+		t.Fatal("Didn't expect the heavy waiter to fit")
+	}
+	if isDone(light) {
+		t.Fatal("Didn't expect the light waiter to overtake the heavy one")
+	}
+
+	l.release(3)
+	if !isDone(heavy) || !isDone(light) {
+		t.Fatal("Expected both waiters to be served")
+	}
+}
+
+func TestLimiterOversizedWeight(t *testing.T) {
+	t.Parallel()
+	l := newLimiter(4, 0)
+	l.acquire(context.Background(), 1, 0)
+	l.resize(2)
+
+	oversized := acquired(func() { l.acquire(context.Background(), 3, 0) })
+	if isDone(oversized) {
+		t.Fatal("Didn't expect the oversized waiter to run alongside others")
+	}
+	l.release(1)
+	if !isDone(oversized) {
+		t.Fatal("Expected the oversized waiter to run alone")
+	}
+}
+
+func TestLimiterAcquireCancel(t *testing.T) {
+	t.Parallel()
+	l := newLimiter(2, 0)
+	l.acquire(context.Background(), 1, 0)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var err error
+	heavy := acquired(func() { err = l.acquire(ctx, 2, 0) })
+	time.Sleep(10 * time.Millisecond)
+	light := acquired(func() { l.acquire(context.Background(), 1, 0) })
+	if isDone(light) {
+		t.Fatal("Didn't expect the light waiter to overtake the heavy one")
+	}
+
+	cancel()
+	if !isDone(heavy) {
+		t.Fatal("Expected acquire to return once its context is cancelled")
+	}
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected a context canceled error, got %v", err)
+	}
+	if !isDone(light) {
+		t.Fatal("Expected the light waiter to be served once the heavy one gave up")
+	}
+}
+
+func TestLimiterTryAcquire(t *testing.T) {
+	t.Parallel()
+	l := newLimiter(2, 0)
+	if !l.tryAcquire(2) {
+		t.Fatal("Expected tryAcquire to succeed below the limit")
+	}
+	if l.tryAcquire(1) {
+		t.Fatal("Didn't expect tryAcquire to succeed at the limit")
+	}
+	l.release(1)
+	if !l.tryAcquire(1) {
+		t.Fatal("Expected tryAcquire to succeed after a release")
+	}
+}
@@ -0,0 +1,492 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"time"
+)
+
+// Option configures optional behavior of a ConMan instance.
+// Options are passed to New after the concurrency limit.
+type Option func(*options)
+
+// options holds the optional settings of a ConMan instance
+type options struct {
+	ordered        bool
+	noAccumulation bool
+	failFast       bool
+	panicRetry     *RetryConfig
+	adaptive       *adaptiveLimit
+	prioritized    bool
+	aging          time.Duration
+	queue          *queueSettings
+	pool           *poolSettings
+	workerHooks    *workerHooks
+	timeout        time.Duration
+	timeoutRetry   *RetryConfig
+	retryPolicies  []retryPolicy
+	retryBudget    *budgetSettings
+	breaker        *BreakerConfig
+	rateLimit      *rateSettings
+	keyLimits      *keySettings
+}
+
+// keySettings holds the settings of the per-key concurrency limits
+type keySettings struct {
+	limit     int64
+	overrides map[string]int64
+}
+
+// rateSettings holds the settings of the rate limit
+type rateSettings struct {
+	tokens   int
+	interval time.Duration
+	burst    int
+}
+
+// budgetSettings holds the settings of the retry budget
+type budgetSettings struct {
+	ratio  float64
+	window time.Duration
+	min    int
+}
+
+// poolSettings holds the settings of the worker pool mode
+type poolSettings struct {
+	size int
+}
+
+// workerHooks holds the functions managing the state of the workers
+type workerHooks struct {
+	init     func(ctx context.Context, worker int) (any, error)
+	teardown func(state any)
+}
+
+// queueSettings holds the settings of the queue mode
+type queueSettings struct {
+	depth  int
+	policy OverflowPolicy
+}
+
+// adaptiveLimit holds the settings of the adaptive concurrency limit
+type adaptiveLimit struct {
+	min       int64
+	max       int64
+	algorithm LimitAlgorithm
+}
+
+// validate checks that the selected options are compatible with each other
+func (o *options) validate() error {
+	if o.ordered && o.noAccumulation {
+		return errors.New("WithOrderedResults cannot be combined with WithoutAccumulation")
+	}
+	if o.panicRetry != nil {
+		if err := o.panicRetry.validate(); err != nil {
+			return fmt.Errorf("invalid panic retry config: %w", err)
+		}
+	}
+	if o.timeout < 0 {
+		return fmt.Errorf("task timeout cannot be negative, got %v", o.timeout)
+	}
+	if o.timeoutRetry != nil {
+		if err := o.timeoutRetry.validate(); err != nil {
+			return fmt.Errorf("invalid timeout retry config: %w", err)
+		}
+	}
+	for _, p := range o.retryPolicies {
+		if len(p.matchers) == 0 {
+			return errors.New("retry policy requires at least one matcher")
+		}
+		if p.config == nil {
+			return errors.New("retry policy config cannot be nil")
+		}
+		if err := p.config.validate(); err != nil {
+			return fmt.Errorf("invalid retry policy config: %w", err)
+		}
+	}
+	if b := o.retryBudget; b != nil {
+		if b.ratio < 0 {
+			return fmt.Errorf("retry budget ratio cannot be negative, got %v", b.ratio)
+		}
+		if b.window <= 0 {
+			return fmt.Errorf("retry budget window must be positive, got %v", b.window)
+		}
+		if b.min < 0 {
+			return fmt.Errorf("retry budget minimum cannot be negative, got %d", b.min)
+		}
+	}
+	if o.breaker != nil {
+		if err := o.breaker.validate(); err != nil {
+			return fmt.Errorf("invalid circuit breaker config: %w", err)
+		}
+	}
+	if r := o.rateLimit; r != nil {
+		if r.tokens < 1 {
+			return fmt.Errorf("rate limit tokens must be at least 1, got %d", r.tokens)
+		}
+		if r.interval < time.Duration(r.tokens) {
+			return fmt.Errorf("rate limit interval must be at least 1ns per token, got %v for %d tokens", r.interval, r.tokens)
+		}
+		if r.burst < 1 {
+			return fmt.Errorf("rate limit burst must be at least 1, got %d", r.burst)
+		}
+	}
+	if k := o.keyLimits; k != nil {
+		if k.limit < 1 {
+			return fmt.Errorf("key concurrency limit must be at least 1, got %d", k.limit)
+		}
+		for key, limit := range k.overrides {
+			if limit < 1 {
+				return fmt.Errorf("concurrency limit of key %q must be at least 1, got %d", key, limit)
+			}
+		}
+	}
+	if o.aging < 0 {
+		return fmt.Errorf("priority aging cannot be negative, got %v", o.aging)
+	}
+	if p := o.pool; p != nil && p.size < 1 {
+		return fmt.Errorf("worker pool size must be at least 1, got %d", p.size)
+	}
+	if h := o.workerHooks; h != nil {
+		if o.pool == nil {
+			return errors.New("WithWorkerInit requires WithWorkerPool")
+		}
+		if h.init == nil {
+			return errors.New("worker init function cannot be nil")
+		}
+	}
+	if q := o.queue; q != nil {
+		if q.depth < 1 {
+			return fmt.Errorf("queue depth must be at least 1, got %d", q.depth)
+		}
+		if q.policy < OverflowBlock || q.policy > OverflowDropNewest {
+			return fmt.Errorf("invalid overflow policy %d", q.policy)
+		}
+	}
+	if a := o.adaptive; a != nil {
+		if a.algorithm == nil {
+			return errors.New("adaptive limit algorithm cannot be nil")
+		}
+		if a.min < 2 {
+			return fmt.Errorf("adaptive limit minimum must be at least 2, got %d", a.min)
+		}
+		if a.max < a.min {
+			return fmt.Errorf("adaptive limit maximum (%d) cannot be less than minimum (%d)", a.max, a.min)
+		}
+	}
+	return nil
+}
+
+// WithOrderedResults enables the ordered mode.
+//
+// In ordered mode, every task dispatched through Run is assigned a sequence
+// number, and its result is kept in a slot at that position. The slots are
+// accessible through OrderedResults(), in submission order.
+//
+// Example:
+//
+//	cm, err := conman.New[int](5, conman.WithOrderedResults())
+func WithOrderedResults() Option {
+	return func(o *options) {
+		o.ordered = true
+	}
+}
+
+// WithoutAccumulation stops the ConMan from keeping task results in memory.
+//
+// Outputs(), Errors() and Results() stay empty, which keeps memory usage flat
+// regardless of the number of tasks. Results should then be consumed as they
+// are produced, through Stream().
+//
+// Example:
+//
+//	cm, err := conman.New[int](5, conman.WithoutAccumulation())
+func WithoutAccumulation() Option {
+	return func(o *options) {
+		o.noAccumulation = true
+	}
+}
+
+// WithFailFast enables the fail-fast mode.
+//
+// In fail-fast mode, the first task failure (after all its retries, if any)
+// cancels the context of all the other running tasks, further calls to Run
+// are rejected with ErrAborted, and Wait returns that first error. A task
+// cancelled on its own, through its handle or the caller's context, doesn't
+// count as a failure.
+//
+// Example:
+//
+//	cm, err := conman.New[int](5, conman.WithFailFast())
+func WithFailFast() Option {
+	return func(o *options) {
+		o.failFast = true
+	}
+}
+
+// WithRetriablePanics makes tasks that panic eligible for retry.
+//
+// Panics are always recovered and recorded as a *PanicError. With this option,
+// the task is also retried according to config, just like if it had returned
+// a *RetriableError.
+//
+// Example:
+//
+//	cm, err := conman.New[int](5, conman.WithRetriablePanics(&conman.RetryConfig{
+//		MaxAttempts:   3,
+//		InitialDelay:  100,
+//		BackoffFactor: 2.0,
+//		MaxDelay:      1000,
+//	}))
+func WithRetriablePanics(config *RetryConfig) Option {
+	return func(o *options) {
+		o.panicRetry = config
+	}
+}
+
+// WithTaskTimeout sets the default timeout of a task execution.
+//
+// Each execution of a task, retries included, gets a context that is cancelled
+// once the timeout elapses. Tasks implementing TimeLimited use their own timeout
+// instead. An execution that fails after its timeout is recorded with an error
+// wrapping ErrTaskTimeout. A timeout of 0 means no timeout.
+//
+// Example:
+//
+//	cm, err := conman.New[int](5, conman.WithTaskTimeout(30*time.Second))
+func WithTaskTimeout(timeout time.Duration) Option {
+	return func(o *options) {
+		o.timeout = timeout
+	}
+}
+
+// WithTimeoutRetry makes tasks that time out eligible for retry.
+//
+// A task execution that exceeds its timeout is retried according to config,
+// just like if it had returned a *RetriableError. Tasks returning their own
+// *RetriableError keep their retry configuration.
+//
+// Example:
+//
+//	cm, err := conman.New[int](5,
+//		conman.WithTaskTimeout(time.Second),
+//		conman.WithTimeoutRetry(&conman.RetryConfig{MaxAttempts: 3}),
+//	)
+func WithTimeoutRetry(config *RetryConfig) Option {
+	return func(o *options) {
+		o.timeoutRetry = config
+	}
+}
+
+// WithRetryPolicy makes the errors matching any of matchers retriable.
+//
+// When a task fails with an error that isn't a *RetriableError, the retry
+// policies are checked in the order they were added. The error is then retried
+// according to the config of the first policy it matches, just like if the task
+// had returned a *RetriableError. This allows retrying the errors of code that
+// doesn't know about ConMan. The option can be used several times.
+//
+// Example:
+//
+//	cm, err := conman.New[int](5,
+//		conman.WithRetryPolicy(&conman.RetryConfig{MaxAttempts: 3, Backoff: &conman.FullJitter{Base: time.Second}},
+//			conman.IsTimeout, conman.IsConnectionError, conman.ErrorIs(io.ErrUnexpectedEOF)),
+//	)
+func WithRetryPolicy(config *RetryConfig, matchers ...ErrorMatcher) Option {
+	return func(o *options) {
+		o.retryPolicies = append(o.retryPolicies, retryPolicy{matchers: matchers, config: config})
+	}
+}
+
+// WithRetryBudget limits the number of retries across all the tasks of the ConMan.
+//
+// Over any sliding window of the given duration, retries may not exceed ratio
+// times the number of tasks started, plus minRetries. This keeps retries from
+// multiplying the load on a failing dependency during an outage. A denied retry
+// stops the retries of the task, which is recorded with a *RetryError wrapping
+// ErrRetryBudgetExhausted and the error of its last attempt.
+//
+// Example:
+//
+//	// Retries may not exceed 10% of the tasks over the last 10 seconds, plus 5
+//	cm, err := conman.New[int](5, conman.WithRetryBudget(0.1, 10*time.Second, 5))
+func WithRetryBudget(ratio float64, window time.Duration, minRetries int) Option {
+	return func(o *options) {
+		o.retryBudget = &budgetSettings{ratio: ratio, window: window, min: minRetries}
+	}
+}
+
+// WithCircuitBreaker enables a circuit breaker per task key (see Keyed).
+//
+// A circuit opens after FailureThreshold consecutive task failures, retries
+// included, and tasks with that key are then rejected right away with
+// ErrCircuitOpen. Once CoolDown has elapsed, up to HalfOpenProbes tasks are let
+// through as probes. The circuit closes again when as many probes succeed, and
+// opens again as soon as one fails. Cancelled tasks don't count. The state of a
+// circuit is available through CircuitState().
+//
+// Example:
+//
+//	cm, err := conman.New[int](5, conman.WithCircuitBreaker(&conman.BreakerConfig{
+//		FailureThreshold: 5,
+//		CoolDown:         30 * time.Second,
+//		HalfOpenProbes:   2,
+//	}))
+func WithCircuitBreaker(config *BreakerConfig) Option {
+	return func(o *options) {
+		o.breaker = config
+	}
+}
+
+// WithRateLimit limits the rate at which task executions start, with a token bucket.
+//
+// The bucket produces tokens per interval, and holds up to burst tokens, so
+// that up to burst executions can start at once after a quiet period. Each
+// execution, retries included, takes a token, waiting for one if the bucket is
+// empty. Tasks wait for their token after getting their slot, so the rate limit
+// and the concurrency limit both apply. Statistics about the waits are
+// available through RateLimitStats().
+//
+// Example:
+//
+//	// Up to 100 executions per second, in bursts of up to 10
+//	cm, err := conman.New[int](20, conman.WithRateLimit(100, time.Second, 10))
+func WithRateLimit(tokens int, interval time.Duration, burst int) Option {
+	return func(o *options) {
+		o.rateLimit = &rateSettings{tokens: tokens, interval: interval, burst: burst}
+	}
+}
+
+// WithKeyLimits limits the number of tasks running concurrently per limit key
+// (see LimitKeyed).
+//
+// Tasks with a given limit key can't run more than limit at once, or the limit
+// of the key in overrides, if any. Both the key limit and the concurrency limit
+// apply.
+// A task waiting for its key doesn't hold a slot of the concurrency limit, so
+// the tasks of a busy key don't hold back the tasks of other keys. In queue and
+// worker pool modes, queued tasks waiting for their key are skipped in favour
+// of the next tasks that can start.
+//
+// Example:
+//
+//	// Up to 3 tasks per customer, 10 for the largest one
+//	cm, err := conman.New[int](50,
+//		conman.WithQueue(1000, conman.OverflowBlock),
+//		conman.WithKeyLimits(3, map[string]int64{"acme": 10}),
+//	)
+func WithKeyLimits(limit int64, overrides map[string]int64) Option {
+	return func(o *options) {
+		o.keyLimits = &keySettings{limit: limit, overrides: maps.Clone(overrides)}
+	}
+}
+
+// WithAdaptiveLimit makes the concurrency limit adapt to the observed task executions.
+//
+// After each execution, algorithm computes a new limit from its latency and
+// error, which is then kept within the [minLimit, maxLimit] bounds. Like the
+// concurrency limit, minLimit must be at least 2. The concurrency limit
+// passed to New is used as the initial limit. The current limit can be
+// monitored through Limit().
+//
+// Example:
+//
+//	cm, err := conman.New[int](10, conman.WithAdaptiveLimit(2, 50, &conman.AIMD{}))
+func WithAdaptiveLimit(minLimit, maxLimit int64, algorithm LimitAlgorithm) Option {
+	return func(o *options) {
+		o.adaptive = &adaptiveLimit{min: minLimit, max: maxLimit, algorithm: algorithm}
+	}
+}
+
+// WithPriorityScheduling enables the priority mode.
+//
+// In priority mode, when the concurrency limit is reached, tasks implementing
+// Prioritized get free slots by decreasing priority rather than in call order.
+// A non-zero aging raises the priority of a waiting task by one level for each
+// aging period it waits, so that low-priority tasks are not starved forever.
+//
+// Example:
+//
+//	// A task waiting for 5 seconds gains one priority level
+//	cm, err := conman.New[int](5, conman.WithPriorityScheduling(5*time.Second))
+func WithPriorityScheduling(aging time.Duration) Option {
+	return func(o *options) {
+		o.prioritized = true
+		o.aging = aging
+	}
+}
+
+// WithQueue enables the queue mode, with a bounded queue of the given depth.
+//
+// In queue mode, tasks are queued and Run returns without waiting for a slot.
+// Tasks start right away while slots are available, so only the tasks waiting
+// for a slot take room in the queue. Queued tasks are dispatched as slots become available, by priority in
+// priority mode, in submission order otherwise. When the queue is full, the
+// overflow policy decides whether Run blocks (OverflowBlock), fails with
+// ErrQueueFull (OverflowReject), or drops a task (OverflowDropOldest and
+// OverflowDropNewest). Dropped tasks are recorded with an ErrDropped error.
+// The number of queued tasks is available through QueueDepth().
+//
+// Example:
+//
+//	cm, err := conman.New[int](5, conman.WithQueue(1000, conman.OverflowReject))
+func WithQueue(depth int, policy OverflowPolicy) Option {
+	return func(o *options) {
+		o.queue = &queueSettings{depth: depth, policy: policy}
+	}
+}
+
+// WithWorkerPool enables the worker pool mode, with the given number of workers.
+//
+// In worker pool mode, tasks are not run on a new goroutine each. Instead, a
+// fixed set of long-lived workers take tasks from the queue and run them one at
+// a time, within the concurrency limit. This reduces the overhead of running
+// large numbers of small tasks.
+//
+// Worker pool mode implies queue mode. If WithQueue isn't used, the queue
+// holds as many tasks as there are workers, with the OverflowBlock policy.
+// The workers run until the ConMan is closed, so Close or Shutdown must be
+// called once the ConMan is no longer needed.
+//
+// Example:
+//
+//	cm, err := conman.New[int](8, conman.WithWorkerPool(8))
+//	// ...
+//	defer cm.Close()
+func WithWorkerPool(workers int) Option {
+	return func(o *options) {
+		o.pool = &poolSettings{size: workers}
+	}
+}
+
+// WithWorkerInit sets up hooks to manage a state for each worker of the pool.
+//
+// init is called once per worker when the ConMan is created, with the index of
+// the worker. The state it returns is made available to the tasks run by that
+// worker through WorkerState. If init fails for any worker, New returns the
+// error. teardown, if not nil, is called with the state of each worker when it
+// stops, after Close or Shutdown. It must be combined with WithWorkerPool.
+//
+// Example:
+//
+//	cm, err := conman.New[int](4,
+//		conman.WithWorkerPool(4),
+//		conman.WithWorkerInit(
+//			func(ctx context.Context, worker int) (any, error) {
+//				return db.Conn(ctx)
+//			},
+//			func(state any) {
+//				state.(*sql.Conn).Close()
+//			},
+//		),
+//	)
+func WithWorkerInit(init func(ctx context.Context, worker int) (any, error), teardown func(state any)) Option {
+	return func(o *options) {
+		o.workerHooks = &workerHooks{init: init, teardown: teardown}
+	}
+}
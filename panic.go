@@ -0,0 +1,26 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import "fmt"
+
+// PanicError is the error recorded for a task that panicked during execution.
+// It holds the value passed to panic and the stack trace of the goroutine.
+type PanicError struct {
+	Value any    // Value passed to panic
+	Stack []byte // Stack trace captured when the panic was recovered
+}
+
+// Error returns a description of the panic value.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("task panicked: %v", e.Value)
+}
+
+// Unwrap returns the panic value if it is an error, nil otherwise.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+	return nil
+}
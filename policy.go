@@ -0,0 +1,84 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrorMatcher reports whether an error returned by a task matches a retry policy
+// (see WithRetryPolicy). Any predicate function can be used as a matcher.
+type ErrorMatcher func(err error) bool
+
+// retryPolicy makes the errors matching any of matchers retriable with config
+type retryPolicy struct {
+	matchers []ErrorMatcher
+	config   *RetryConfig
+}
+
+// ErrorIs returns a matcher for errors that match any of targets, as per errors.Is.
+//
+// Example:
+//
+//	conman.ErrorIs(io.ErrUnexpectedEOF, sql.ErrConnDone)
+func ErrorIs(targets ...error) ErrorMatcher {
+	return func(err error) bool {
+		for _, target := range targets {
+			if errors.Is(err, target) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ErrorAs returns a matcher for errors that have an error of type E in their
+// chain, as per errors.As.
+//
+// Example:
+//
+//	conman.ErrorAs[*url.Error]()
+func ErrorAs[E error]() ErrorMatcher {
+	return func(err error) bool {
+		var target E
+		return errors.As(err, &target)
+	}
+}
+
+// IsTimeout matches task timeouts (see ErrTaskTimeout), whatever the error
+// returned by the task, and errors that report themselves as timeouts through
+// a Timeout() bool method, such as a net.Error or context.DeadlineExceeded.
+func IsTimeout(err error) bool {
+	if errors.Is(err, ErrTaskTimeout) {
+		return true
+	}
+	var t interface{ Timeout() bool }
+	return errors.As(err, &t) && t.Timeout()
+}
+
+// IsDeadlineExceeded matches errors caused by an expired context deadline.
+func IsDeadlineExceeded(err error) bool {
+	return errors.Is(err, context.DeadlineExceeded)
+}
+
+// classify makes an error retriable if it matches a retry policy, the first
+// matching policy being used. Errors that are already a *RetriableError are
+// returned as is.
+func (c *ConMan[T]) classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	if _, ok := err.(*RetriableError); ok {
+		return err
+	}
+	for _, p := range c.opts.retryPolicies {
+		for _, match := range p.matchers {
+			if match(err) {
+				return &RetriableError{Err: err, RetryConfig: p.config}
+			}
+		}
+	}
+	return err
+}
@@ -0,0 +1,27 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+//go:build !plan9
+
+package conman
+
+import (
+	"errors"
+	"syscall"
+)
+
+// IsConnectionError matches system call errors caused by a lost or refused
+// connection, which are usually transient: ECONNREFUSED, ECONNRESET,
+// ECONNABORTED, EPIPE, ETIMEDOUT and EHOSTUNREACH.
+func IsConnectionError(err error) bool {
+	var errno syscall.Errno
+	if !errors.As(err, &errno) {
+		return false
+	}
+	switch errno {
+	case syscall.ECONNREFUSED, syscall.ECONNRESET, syscall.ECONNABORTED,
+		syscall.EPIPE, syscall.ETIMEDOUT, syscall.EHOSTUNREACH:
+		return true
+	}
+	return false
+}
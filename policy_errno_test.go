@@ -0,0 +1,33 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+//go:build !plan9
+
+package conman
+
+import (
+	"net"
+	"os"
+	"syscall"
+	"testing"
+)
+
+func TestIsConnectionError(t *testing.T) {
+	t.Parallel()
+	reset := &net.OpError{Op: "read", Err: os.NewSyscallError("read", syscall.ECONNRESET)}
+	netTimeout := &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}
+
+	for _, err := range []error{reset, syscall.ECONNREFUSED} {
+		if !IsConnectionError(err) {
+			t.Errorf("Expected %v to match", err)
+		}
+	}
+	for _, err := range []error{syscall.ENOENT, netTimeout, errBoom} {
+		if IsConnectionError(err) {
+			t.Errorf("Didn't expect %v to match", err)
+		}
+	}
+	if IsTimeout(reset) {
+		t.Errorf("Didn't expect %v to be a timeout", reset)
+	}
+}
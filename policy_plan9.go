@@ -0,0 +1,12 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+//go:build plan9
+
+package conman
+
+// IsConnectionError matches system call errors caused by a lost or refused
+// connection. Plan 9 has no errno values, so it never matches there.
+func IsConnectionError(err error) bool {
+	return false
+}
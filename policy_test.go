@@ -0,0 +1,87 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"os"
+	"testing"
+)
+
+func TestErrorMatchers(t *testing.T) {
+	t.Parallel()
+	wrapped := fmt.Errorf("read failed: %w", io.ErrUnexpectedEOF)
+	pathErr := fmt.Errorf("open: %w", &fs.PathError{Op: "open", Path: "x", Err: fs.ErrNotExist})
+	netTimeout := &net.OpError{Op: "dial", Err: os.ErrDeadlineExceeded}
+
+	tests := []struct {
+		name    string
+		matcher ErrorMatcher
+		matches []error
+		misses  []error
+	}{
+		{"ErrorIs", ErrorIs(io.EOF, io.ErrUnexpectedEOF), []error{io.EOF, wrapped}, []error{errBoom}},
+		{"ErrorAs", ErrorAs[*fs.PathError](), []error{pathErr}, []error{wrapped}},
+		{"IsTimeout", IsTimeout, []error{netTimeout, context.DeadlineExceeded, fmt.Errorf("%w: %w", ErrTaskTimeout, context.DeadlineExceeded), fmt.Errorf("%w: %w", ErrTaskTimeout, errBoom)}, []error{pathErr, errBoom}},
+		{"IsDeadlineExceeded", IsDeadlineExceeded, []error{fmt.Errorf("query: %w", context.DeadlineExceeded)}, []error{context.Canceled}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, err := range tt.matches {
+				if !tt.matcher(err) {
+					t.Errorf("Expected %v to match", err)
+				}
+			}
+			for _, err := range tt.misses {
+				if tt.matcher(err) {
+					t.Errorf("Didn't expect %v to match", err)
+				}
+			}
+		})
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4,
+		WithRetryPolicy(&RetryConfig{MaxAttempts: 1}, ErrorIs(io.ErrUnexpectedEOF)),
+		WithRetryPolicy(&RetryConfig{MaxAttempts: 5}, func(err error) bool { return err == io.ErrUnexpectedEOF }, ErrorIs(io.ErrClosedPipe)),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	eof := &scriptedtask{errs: []error{io.ErrUnexpectedEOF, io.ErrUnexpectedEOF}}
+	closed := &scriptedtask{errs: []error{io.ErrClosedPipe, io.ErrClosedPipe, io.ErrClosedPipe}}
+	permanent := &scriptedtask{errs: []error{errBoom}}
+	cm.Run(ctx, eof)
+	cm.Run(ctx, closed)
+	cm.Run(ctx, permanent)
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+
+	for _, r := range cm.Results() {
+		switch r.Task {
+		case eof:
+			if !errors.Is(r.Err, io.ErrUnexpectedEOF) || r.Attempts != 2 {
+				t.Errorf("Expected the first matching policy to allow a single retry, got %+v", r)
+			}
+		case closed:
+			if r.Err != nil || r.Attempts != 4 {
+				t.Errorf("Expected the task to succeed on its fourth attempt, got %+v", r)
+			}
+		case permanent:
+			if r.Err != errBoom || r.Attempts != 1 {
+				t.Errorf("Expected an unmatched error not to be retried, got %+v", r)
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"fmt"
+)
+
+// workerStateKey is the context key of the worker state
+type workerStateKey struct{}
+
+// WorkerState returns the state of the worker running a task, as returned by
+// the init function passed to WithWorkerInit. It is meant to be called from
+// Execute, with the context received by the task.
+//
+// Returns:
+//   - S: The worker state
+//   - bool: Whether the task runs on a worker with a state of type S
+//
+// Example:
+//
+//	func (t *query) Execute(ctx context.Context) (int, error) {
+//		db, ok := conman.WorkerState[*sql.Conn](ctx)
+//		if !ok {
+//			return 0, errors.New("no connection")
+//		}
+//		// ...
+//	}
+func WorkerState[S any](ctx context.Context) (S, bool) {
+	s, ok := ctx.Value(workerStateKey{}).(S)
+	return s, ok
+}
+
+// startWorkers initializes and starts the goroutines of the worker pool.
+// If a worker fails to initialize, the ones already initialized are torn
+// down and no worker is started.
+func (c *ConMan[T]) startWorkers(size int) error {
+	states := make([]any, size)
+	if h := c.opts.workerHooks; h != nil {
+		for i := range size {
+			state, err := h.init(c.ctx, i)
+			if err != nil {
+				if h.teardown != nil {
+					for _, s := range states[:i] {
+						h.teardown(s)
+					}
+				}
+				return fmt.Errorf("failed to initialize worker %d: %w", i, err)
+			}
+			states[i] = state
+		}
+	}
+
+	c.workers.Add(size)
+	for _, state := range states {
+		go c.work(state)
+	}
+	return nil
+}
+
+// work is the loop of a worker, running queued jobs one at a time until
+// the ConMan is closed and its queue drained
+func (c *ConMan[T]) work(state any) {
+	defer c.workers.Done()
+	h := c.opts.workerHooks
+	if h != nil && h.teardown != nil {
+		defer h.teardown(state)
+	}
+	for j := c.take(true); j != nil; j = c.take(true) {
+		if h != nil {
+			j.ctx = context.WithValue(j.ctx, workerStateKey{}, state)
+		}
+		c.run(j)
+	}
+}
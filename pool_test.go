@@ -0,0 +1,280 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWorkerPool(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4, WithWorkerPool(4))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	defer cm.Close()
+
+	g := &gauge{}
+	for range 12 {
+		if err := cm.Run(ctx, &gaugedtask{gauge: g, delay: 20 * time.Millisecond}); err != nil {
+			t.Fatalf("Run returned an unexpected error: %v", err)
+		}
+	}
+	for i := range 10 {
+		if err := cm.Run(ctx, &doubler{operand: i}); err != nil {
+			t.Fatalf("Run returned an unexpected error: %v", err)
+		}
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+
+	if len(cm.Outputs()) != 22 {
+		t.Errorf("Expected 22 outputs, got %d", len(cm.Outputs()))
+	}
+	if g.max() != 4 {
+		t.Errorf("Expected 4 tasks to run concurrently, got %d", g.max())
+	}
+}
+
+func TestWorkerPoolSmallerThanLimit(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](8, WithWorkerPool(2))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	defer cm.Close()
+
+	g := &gauge{}
+	for range 6 {
+		cm.Run(ctx, &gaugedtask{gauge: g, delay: 20 * time.Millisecond})
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if g.max() != 2 {
+		t.Errorf("Expected as many concurrent tasks as workers, got %d", g.max())
+	}
+}
+
+func TestWorkerPoolBurstWithIdleWorkers(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4, WithWorkerPool(4), WithQueue(1, OverflowReject))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	defer cm.Close()
+	// Let the workers wait for tasks
+	time.Sleep(20 * time.Millisecond)
+
+	for i := range 4 {
+		if err := cm.TryRun(ctx, &sleepydoubler{operand: i, delay: 50 * time.Millisecond}); err != nil {
+			t.Errorf("Expected task %d to be handed over to an idle worker, got %v", i, err)
+		}
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if len(cm.Outputs()) != 4 {
+		t.Errorf("Expected 4 outputs, got %v", cm.Outputs())
+	}
+}
+
+func TestWorkerPoolClose(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4, WithWorkerPool(4), WithQueue(10, OverflowBlock))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	for i := range 8 {
+		cm.Run(ctx, &slowdoubler{operand: i, delayInMiliseconds: 10})
+	}
+	cm.Close()
+	select {
+	case <-cm.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected the workers to stop once the queue is drained")
+	}
+	if len(cm.Outputs()) != 8 {
+		t.Errorf("Expected queued tasks to run before the workers stop, got %d outputs", len(cm.Outputs()))
+	}
+}
+
+func TestWorkerPoolCloseWhileRunning(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	for range 50 {
+		cm, err := New[int](2, WithWorkerPool(1))
+		if err != nil {
+			t.Fatalf("Failed to create ConMan: %v", err)
+		}
+
+		started := make(chan struct{})
+		submitted := make(chan int)
+		go func() {
+			accepted := 0
+			for i := range 5 {
+				if cm.Run(ctx, &doubler{operand: i}) == nil {
+					accepted++
+				}
+				if i == 0 {
+					close(started)
+				}
+			}
+			submitted <- accepted
+		}()
+		<-started
+		cm.Close()
+		accepted := <-submitted
+
+		select {
+		case <-cm.Done():
+		case <-time.After(time.Second):
+			t.Fatalf("Expected the ConMan to terminate, %d tasks still queued", cm.QueueDepth())
+		}
+		if len(cm.Results()) != accepted {
+			t.Fatalf("Expected the %d accepted tasks to run, got %d results", accepted, len(cm.Results()))
+		}
+	}
+}
+
+// workertask reports the state of the worker running it
+type workertask struct{}
+
+func (t *workertask) Execute(ctx context.Context) (int, error) {
+	worker, ok := WorkerState[int](ctx)
+	if !ok {
+		return 0, errors.New("no worker state")
+	}
+	return worker, nil
+}
+
+func TestWorkerInit(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+
+	var mu sync.Mutex
+	var tornDown []int
+	cm, err := New[int](3,
+		WithWorkerPool(3),
+		WithWorkerInit(
+			func(ctx context.Context, worker int) (any, error) {
+				return worker, nil
+			},
+			func(state any) {
+				mu.Lock()
+				defer mu.Unlock()
+				tornDown = append(tornDown, state.(int))
+			},
+		),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	for range 20 {
+		cm.Run(ctx, &workertask{})
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if len(cm.Errors()) != 0 {
+		t.Fatalf("Expected every task to get a worker state, got %v", cm.Errors())
+	}
+	for _, worker := range cm.Outputs() {
+		if worker < 0 || worker > 2 {
+			t.Errorf("Expected a worker index between 0 and 2, got %d", worker)
+		}
+	}
+
+	cm.Close()
+	<-cm.Done()
+	mu.Lock()
+	defer mu.Unlock()
+	if len(tornDown) != 3 {
+		t.Errorf("Expected the 3 workers to be torn down, got %v", tornDown)
+	}
+}
+
+func TestWorkerInitFailure(t *testing.T) {
+	t.Parallel()
+	boom := errors.New("boom")
+	var tornDown []int
+	_, err := New[int](4,
+		WithWorkerPool(4),
+		WithWorkerInit(
+			func(ctx context.Context, worker int) (any, error) {
+				if worker == 2 {
+					return nil, boom
+				}
+				return worker, nil
+			},
+			func(state any) {
+				tornDown = append(tornDown, state.(int))
+			},
+		),
+	)
+	if !errors.Is(err, boom) {
+		t.Fatalf("Expected the init error, got %v", err)
+	}
+	if len(tornDown) != 2 || tornDown[0] != 0 || tornDown[1] != 1 {
+		t.Errorf("Expected the workers initialized before the failure to be torn down, got %v", tornDown)
+	}
+}
+
+func TestWorkerStateWithoutInit(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithWorkerPool(2))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	defer cm.Close()
+
+	cm.Run(ctx, &workertask{})
+	cm.Wait(ctx)
+	if len(cm.Errors()) != 1 {
+		t.Errorf("Expected no worker state without WithWorkerInit, got %v", cm.Outputs())
+	}
+}
+
+// benchmarkLimit returns the concurrency limit used in benchmarks
+func benchmarkLimit() int64 {
+	return max(2, int64(runtime.GOMAXPROCS(0)))
+}
+
+func benchmarkRun(b *testing.B, opts ...Option) {
+	ctx := b.Context()
+	cm, err := New[int](benchmarkLimit(), opts...)
+	if err != nil {
+		b.Fatalf("Failed to create ConMan: %v", err)
+	}
+	defer cm.Close()
+
+	task := &doubler{operand: 1}
+	for b.Loop() {
+		cm.Run(ctx, task)
+	}
+	if err := cm.Wait(ctx); err != nil {
+		b.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+}
+
+func BenchmarkSpawnPerTask(b *testing.B) {
+	benchmarkRun(b, WithoutAccumulation())
+}
+
+func BenchmarkWorkerPool(b *testing.B) {
+	benchmarkRun(b, WithoutAccumulation(), WithWorkerPool(int(benchmarkLimit())))
+}
@@ -0,0 +1,399 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+// OverflowPolicy defines what happens when a task is submitted to a full queue.
+type OverflowPolicy int
+
+const (
+	// OverflowBlock makes submitters wait until there is room in the queue.
+	OverflowBlock OverflowPolicy = iota
+	// OverflowReject makes submissions fail with ErrQueueFull.
+	OverflowReject
+	// OverflowDropOldest drops the task that has been queued the longest to make room.
+	OverflowDropOldest
+	// OverflowDropNewest drops the submitted task.
+	OverflowDropNewest
+)
+
+// ErrQueueFull is returned when submitting a task to a full queue
+// with the OverflowReject policy.
+var ErrQueueFull = errors.New("conman: queue is full")
+
+// ErrDropped is the error recorded for tasks dropped from a full queue
+// with the OverflowDropOldest and OverflowDropNewest policies.
+var ErrDropped = errors.New("conman: task dropped from the queue")
+
+// queue is a bounded queue of jobs waiting for slots.
+// Jobs are dequeued in the same order as the limiter serves its waiters.
+//
+// Jobs are queued in a lane per limit key, so that the next job that can start
+// is found without going through the jobs held back by their key limit.
+type queue[T any] struct {
+	mu          sync.Mutex
+	ready       *sync.Cond // Signalled when a job is queued or handed over, or the queue is closed
+	sched       *scheduler
+	lanes       map[string]*lane[T] // Queued jobs by limit key
+	runnable    pqueue[*lane[T]]    // Lanes whose key allows their first job to start
+	size        int                 // Number of queued jobs
+	room        *limiter            // One unit per queued job
+	policy      OverflowPolicy
+	handoff     []*job[T] // Jobs holding their slots, waiting for an idle worker
+	idle        int       // Workers waiting for a job
+	dispatching bool
+	closed      bool
+}
+
+// newQueue creates a queue holding up to depth jobs
+func newQueue[T any](depth int, policy OverflowPolicy, aging time.Duration) *queue[T] {
+	q := &queue[T]{
+		sched:  newScheduler(aging),
+		lanes:  make(map[string]*lane[T]),
+		room:   newLimiter(int64(depth), aging),
+		policy: policy,
+	}
+	q.runnable.sched = q.sched
+	q.ready = sync.NewCond(&q.mu)
+	return q
+}
+
+// lane holds the queued jobs of a limit key. Its ticket is a copy of the
+// ticket of its first job, so that lanes are ordered like their first jobs.
+type lane[T any] struct {
+	ticket
+	key  string
+	jobs pqueue[*job[T]]
+}
+
+// close wakes up the workers waiting for jobs, so that they can stop
+// once the queue is drained
+func (q *queue[T]) close() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.closed = true
+	q.ready.Broadcast()
+}
+
+// QueueDepth returns the number of tasks waiting in the queue.
+// It always returns 0 outside of queue mode (see WithQueue).
+func (c *ConMan[T]) QueueDepth() int {
+	if c.queue == nil {
+		return 0
+	}
+	c.queue.mu.Lock()
+	defer c.queue.mu.Unlock()
+	return c.queue.size
+}
+
+// enqueue starts a job right away if its slots are available, and otherwise
+// adds it to the queue, applying the overflow policy if it is full, and makes
+// sure the queue is being dispatched, unless workers take care of it.
+// If block is false, it fails with ErrAtCapacity instead of waiting for room.
+func (c *ConMan[T]) enqueue(j *job[T], block bool) error {
+	q := c.queue
+	if c.start(j) {
+		return nil
+	}
+	if q.policy == OverflowBlock {
+		if !block {
+			if !q.room.tryAcquire(1) {
+				return ErrAtCapacity
+			}
+		} else if err := q.room.acquire(j.ctx, 1, j.priority); err != nil {
+			return err
+		}
+		q.mu.Lock()
+		c.push(j)
+		q.mu.Unlock()
+	} else {
+		dropped, err := c.offer(j)
+		if err != nil {
+			return err
+		}
+		if dropped != nil {
+			c.finish(dropped, Result[T]{Seq: dropped.seq, Task: dropped.task, Err: ErrDropped})
+		}
+	}
+	if c.opts.pool == nil {
+		c.startDispatcher()
+	}
+	return nil
+}
+
+// start runs a job right away if no job is queued and its slots are available,
+// so that only the jobs actually waiting for slots take room in the queue.
+// In worker pool mode, the job is handed over to an idle worker, if any.
+// It reports whether the job was started.
+func (c *ConMan[T]) start(j *job[T]) bool {
+	q := c.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.size > 0 || (c.opts.pool != nil && q.idle <= len(q.handoff)) {
+		return false
+	}
+	if c.reserveKey(j, false) != nil {
+		return false
+	}
+	if !c.limiter.tryAcquire(j.weight) {
+		if c.keys != nil && j.limitKey != "" {
+			// The queue is empty, no need to wake it up like releaseKey does
+			c.keys.release(j.limitKey)
+		}
+		return false
+	}
+	j.seq = c.nextSeq()
+	if c.opts.pool != nil {
+		q.handoff = append(q.handoff, j)
+		q.ready.Broadcast()
+		return true
+	}
+	go c.run(j)
+	return true
+}
+
+// offer adds a job to the queue without blocking. If the queue is full,
+// it applies the overflow policy and returns the dropped job, if any.
+func (c *ConMan[T]) offer(j *job[T]) (*job[T], error) {
+	q := c.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.room.tryAcquire(1) {
+		c.push(j)
+		return nil, nil
+	}
+	switch q.policy {
+	case OverflowDropNewest:
+		j.seq = c.nextSeq()
+		return j, nil
+	case OverflowDropOldest:
+		var oldest *job[T]
+		for _, l := range q.lanes {
+			for _, queued := range l.jobs.items {
+				if oldest == nil || queued.arrival < oldest.arrival {
+					oldest = queued
+				}
+			}
+		}
+		c.remove(oldest)
+		// The room of the dropped job is handed over to the new one
+		c.push(j)
+		return oldest, nil
+	default:
+		return nil, ErrQueueFull
+	}
+}
+
+// push assigns a sequence number to a job and adds it to the lane of its key.
+// The job is removed from the queue as soon as its context is done.
+// Must be called with the queue mutex held, and room reserved for the job.
+func (c *ConMan[T]) push(j *job[T]) {
+	q := c.queue
+	j.seq = c.nextSeq()
+	q.sched.stamp(&j.ticket, j.priority)
+	l, ok := q.lanes[j.limitKey]
+	if !ok {
+		l = &lane[T]{key: j.limitKey}
+		l.index = -1
+		l.jobs.sched = q.sched
+		q.lanes[j.limitKey] = l
+	}
+	heap.Push(&l.jobs, j)
+	q.size++
+	c.updateLane(l)
+	j.unwatch = context.AfterFunc(j.ctx, func() { c.unqueue(j) })
+	q.ready.Signal()
+}
+
+// remove removes a job from the queue. Must be called with the queue mutex held.
+func (c *ConMan[T]) remove(j *job[T]) {
+	q := c.queue
+	j.unwatch()
+	l := q.lanes[j.limitKey]
+	heap.Remove(&l.jobs, j.index)
+	q.size--
+	c.updateLane(l)
+}
+
+// unqueue removes a job whose context is done from the queue, if it is still
+// queued, and fails it without running it, freeing its room right away
+func (c *ConMan[T]) unqueue(j *job[T]) {
+	q := c.queue
+	q.mu.Lock()
+	queued := j.index >= 0
+	if queued {
+		c.remove(j)
+		q.room.release(1)
+		// The dispatcher may be waiting for the jobs held back by their key
+		q.ready.Broadcast()
+	}
+	q.mu.Unlock()
+	if queued {
+		c.finish(j, Result[T]{Seq: j.seq, Task: j.task, Err: j.ctx.Err()})
+	}
+}
+
+// updateLane keeps a lane among the runnable ones as long as it has jobs and
+// its key allows one more job to start, ordered by its first job. Empty lanes
+// are dropped. Must be called with the queue mutex held, whenever the jobs of
+// the lane or the availability of its key change.
+func (c *ConMan[T]) updateLane(l *lane[T]) {
+	q := c.queue
+	if l.jobs.Len() == 0 {
+		if l.index >= 0 {
+			heap.Remove(&q.runnable, l.index)
+		}
+		delete(q.lanes, l.key)
+		return
+	}
+	first := l.jobs.items[0]
+	l.priority, l.enqueued, l.arrival = first.priority, first.enqueued, first.arrival
+	runnable := l.key == "" || c.keys.available(l.key)
+	switch {
+	case runnable && l.index >= 0:
+		heap.Fix(&q.runnable, l.index)
+	case runnable:
+		heap.Push(&q.runnable, l)
+	case l.index >= 0:
+		heap.Remove(&q.runnable, l.index)
+	}
+}
+
+// next returns a job handed over to the workers, which already holds its slots,
+// or else the weight of the next job in the queue that can start.
+// If the queue is empty and wait is false, it returns false and marks the queue
+// as no longer being dispatched. If wait is true, it waits for a job to be
+// queued or handed over, and returns false once the queue is closed and drained.
+// Either way, it waits while all the queued jobs are held back by their key limit.
+func (c *ConMan[T]) next(wait bool) (*job[T], int64, bool) {
+	q := c.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for {
+		if len(q.handoff) > 0 {
+			j := q.handoff[0]
+			q.handoff[0] = nil
+			q.handoff = q.handoff[1:]
+			return j, j.weight, true
+		}
+		if j := c.eligible(); j != nil {
+			return nil, j.weight, true
+		}
+		if q.size == 0 {
+			if !wait {
+				q.dispatching = false
+				return nil, 0, false
+			}
+			if q.closed {
+				return nil, 0, false
+			}
+		}
+		if wait {
+			q.idle++
+			q.ready.Wait()
+			q.idle--
+		} else {
+			q.ready.Wait()
+		}
+	}
+}
+
+// dequeue removes the next job that can start from the queue, along with
+// a slot of its key, if its weight is at most maxWeight, and returns nil otherwise
+func (c *ConMan[T]) dequeue(maxWeight int64) *job[T] {
+	q := c.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j := c.eligible()
+	if j == nil || j.weight > maxWeight {
+		return nil
+	}
+	// Key slots are only taken with the queue mutex held in queue mode,
+	// so the slot found by eligible is still available
+	if err := c.reserveKey(j, false); err != nil {
+		return nil
+	}
+	c.remove(j)
+	q.room.release(1)
+	return j
+}
+
+// eligible returns the next job in the queue whose key limit, if any, allows
+// it to start. Jobs held back by their key are skipped, so that they don't
+// block the jobs of other keys. Must be called with the queue mutex held.
+func (c *ConMan[T]) eligible() *job[T] {
+	q := c.queue
+	if q.runnable.Len() == 0 {
+		return nil
+	}
+	return q.runnable.items[0].jobs.items[0]
+}
+
+// startDispatcher starts a goroutine dispatching queued jobs, unless one is
+// already running. The goroutine stops once the queue is empty.
+func (c *ConMan[T]) startDispatcher() {
+	q := c.queue
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.dispatching || q.size == 0 {
+		return
+	}
+	q.dispatching = true
+	go c.dispatchQueued()
+}
+
+// dispatchQueued runs queued jobs as slots become available, until the queue is empty
+func (c *ConMan[T]) dispatchQueued() {
+	for j := c.take(false); j != nil; j = c.take(false) {
+		go c.run(j)
+	}
+}
+
+// take returns the next queued job, once its slots are reserved.
+// If the queue is empty, it returns nil unless wait is true, in which case it
+// waits for a job and only returns nil once the queue is closed and drained.
+//
+// Slots are reserved before a job is dequeued, so that a job queued while
+// waiting for slots can still be taken first if it has a higher priority.
+func (c *ConMan[T]) take(wait bool) *job[T] {
+	for {
+		j, weight, ok := c.next(wait)
+		if !ok {
+			return nil
+		}
+		if j != nil {
+			return j
+		}
+		if err := c.limiter.acquire(c.ctx, weight, 0); err != nil {
+			// The ConMan is shutting down, fail the next job without running it
+			if j := c.dequeue(math.MaxInt64); j != nil {
+				c.releaseKey(j)
+				c.finish(j, Result[T]{Seq: j.seq, Task: j.task, Err: err})
+			}
+			continue
+		}
+		j = c.dequeue(weight)
+		if j == nil {
+			// Another job got to the head of the queue in the meantime
+			c.limiter.release(weight)
+			continue
+		}
+		c.limiter.release(weight - j.weight)
+		if err := j.ctx.Err(); err != nil {
+			c.limiter.release(j.weight)
+			c.releaseKey(j)
+			c.finish(j, Result[T]{Seq: j.seq, Task: j.task, Err: err})
+			continue
+		}
+		return j
+	}
+}
@@ -0,0 +1,289 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fillQueue occupies the 2 slots of cm with long running tasks, finishing
+// 50ms apart, then queues n more tasks
+func fillQueue(t *testing.T, ctx context.Context, cm *ConMan[int], n int) {
+	t.Helper()
+	for i := range 2 + n {
+		delay := 200*time.Millisecond + time.Duration(i)*50*time.Millisecond
+		if err := cm.Run(ctx, &sleepydoubler{operand: i, delay: delay}); err != nil {
+			t.Fatalf("Run returned an unexpected error: %v", err)
+		}
+	}
+}
+
+func TestQueue(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithQueue(10, OverflowBlock))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	start := time.Now()
+	for i := range 6 {
+		if err := cm.Run(ctx, &slowdoubler{operand: i}); err != nil {
+			t.Fatalf("Run returned an unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Errorf("Expected Run not to block while there is room in the queue, took %v", elapsed)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if depth := cm.QueueDepth(); depth != 4 {
+		t.Errorf("Expected 4 queued tasks, got %d", depth)
+	}
+
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if len(cm.Outputs()) != 6 {
+		t.Errorf("Expected 6 outputs, got %v", cm.Outputs())
+	}
+	if depth := cm.QueueDepth(); depth != 0 {
+		t.Errorf("Expected an empty queue, got %d queued tasks", depth)
+	}
+}
+
+func TestQueueBlock(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithQueue(1, OverflowBlock))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	fillQueue(t, ctx, cm, 1)
+
+	if err := cm.TryRun(ctx, &doubler{operand: 1}); !errors.Is(err, ErrAtCapacity) {
+		t.Errorf("Expected ErrAtCapacity from TryRun on a full queue, got %v", err)
+	}
+	dispatchCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := cm.Run(dispatchCtx, &doubler{operand: 1}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected Run to block until its deadline on a full queue, got %v", err)
+	}
+	if err := cm.Run(ctx, &doubler{operand: 100}); err != nil {
+		t.Errorf("Expected Run to wait for room in the queue, got %v", err)
+	}
+
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if !slices.Contains(cm.Outputs(), 200) {
+		t.Errorf("Expected output %v is not part of the captured outputs", 200)
+	}
+}
+
+func TestQueueReject(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithQueue(1, OverflowReject))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	fillQueue(t, ctx, cm, 1)
+
+	if err := cm.Run(ctx, &doubler{operand: 1}); !errors.Is(err, ErrQueueFull) {
+		t.Errorf("Expected ErrQueueFull, got %v", err)
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if len(cm.Results()) != 3 {
+		t.Errorf("Expected 3 results, got %d", len(cm.Results()))
+	}
+}
+
+func TestQueueDropNewest(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithQueue(1, OverflowDropNewest))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	fillQueue(t, ctx, cm, 1)
+
+	h, err := cm.Submit(ctx, &doubler{operand: 1})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	if _, err := h.Await(ctx); !errors.Is(err, ErrDropped) {
+		t.Errorf("Expected the newest task to be dropped, got %v", err)
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if len(cm.Outputs()) != 3 {
+		t.Errorf("Expected the queued task to run, got outputs %v", cm.Outputs())
+	}
+}
+
+func TestQueueDropOldest(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithQueue(1, OverflowDropOldest))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	fillQueue(t, ctx, cm, 0)
+
+	oldest, err := cm.Submit(ctx, &doubler{operand: 1})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	newest, err := cm.Submit(ctx, &doubler{operand: 2})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	if _, err := oldest.Await(ctx); !errors.Is(err, ErrDropped) {
+		t.Errorf("Expected the oldest task to be dropped, got %v", err)
+	}
+	if out, err := newest.Await(ctx); err != nil || out != 4 {
+		t.Errorf("Expected the newest task to run, got %d (error: %v)", out, err)
+	}
+}
+
+func TestQueueDropFailFast(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithQueue(1, OverflowDropNewest), WithFailFast())
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	fillQueue(t, ctx, cm, 1)
+
+	h, err := cm.Submit(ctx, &doubler{operand: 1})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	if _, err := h.Await(ctx); !errors.Is(err, ErrDropped) {
+		t.Errorf("Expected the newest task to be dropped, got %v", err)
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("Expected Wait not to report the dropped task as a failure, got %v", err)
+	}
+	if len(cm.Outputs()) != 3 {
+		t.Errorf("Expected the running and queued tasks to complete, got outputs %v", cm.Outputs())
+	}
+}
+
+func TestQueueCancel(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithQueue(1, OverflowReject))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	fillQueue(t, ctx, cm, 0)
+
+	h, err := cm.Submit(ctx, &doubler{operand: 1})
+	if err != nil {
+		t.Fatalf("Submit returned an unexpected error: %v", err)
+	}
+	h.Cancel()
+	awaitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	if _, err := h.Await(awaitCtx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected the queued task to be cancelled right away, got %v", err)
+	}
+	if depth := cm.QueueDepth(); depth != 0 {
+		t.Errorf("Expected the cancelled task to leave the queue, got %d queued tasks", depth)
+	}
+	if err := cm.Run(ctx, &doubler{operand: 2}); err != nil {
+		t.Errorf("Expected the room of the cancelled task to be freed, got %v", err)
+	}
+
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if !slices.Contains(cm.Outputs(), 4) || len(cm.Outputs()) != 3 {
+		t.Errorf("Expected the running and newly queued tasks to complete, got outputs %v", cm.Outputs())
+	}
+}
+
+func TestQueueBurstWithinLimit(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	for _, policy := range []OverflowPolicy{OverflowBlock, OverflowReject, OverflowDropOldest, OverflowDropNewest} {
+		cm, err := New[int](8, WithQueue(2, policy))
+		if err != nil {
+			t.Fatalf("Failed to create ConMan: %v", err)
+		}
+		// More tasks than the queue depth, all submitted at once
+		for i := range 8 {
+			if err := cm.TryRun(ctx, &sleepydoubler{operand: i, delay: 50 * time.Millisecond}); err != nil {
+				t.Errorf("Expected policy %d to start task %d right away, got %v", policy, i, err)
+			}
+		}
+		if depth := cm.QueueDepth(); depth != 0 {
+			t.Errorf("Expected policy %d not to queue tasks while slots are free, got %d queued tasks", policy, depth)
+		}
+		if err := cm.Wait(ctx); err != nil {
+			t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+		}
+		if len(cm.Errors()) != 0 {
+			t.Errorf("Expected no task to be dropped with policy %d, got %v", policy, cm.Errors())
+		}
+		if len(cm.Outputs()) != 8 {
+			t.Errorf("Expected 8 outputs with policy %d, got %v", policy, cm.Outputs())
+		}
+	}
+}
+
+func TestQueuePriority(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithQueue(10, OverflowBlock), WithPriorityScheduling(0))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	fillQueue(t, ctx, cm, 0)
+
+	var mu sync.Mutex
+	var started []int
+	for _, p := range []int{1, 3, 2} {
+		cm.Run(ctx, &prioritytask{priority: p, mu: &mu, started: &started})
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if !slices.Equal(started, []int{3, 2, 1}) {
+		t.Errorf("Expected queued tasks to start by decreasing priority, got %v", started)
+	}
+}
+
+func TestQueueShutdown(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2, WithQueue(10, OverflowBlock))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+	for i := range 4 {
+		cm.Run(ctx, &sleepydoubler{operand: i, delay: 5 * time.Second})
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	cm.Shutdown(shutdownCtx)
+	select {
+	case <-cm.Done():
+	case <-time.After(time.Second):
+		t.Fatal("Expected running and queued tasks to be cancelled")
+	}
+	if len(cm.Errors()) != 4 {
+		t.Errorf("Expected 4 cancellation errors, got %v", cm.Errors())
+	}
+}
@@ -0,0 +1,113 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RateLimitStats describes how task executions were delayed by the rate limit
+// (see WithRateLimit).
+type RateLimitStats struct {
+	Executions int64         // Number of executions that got a token
+	Delayed    int64         // Number of executions that had to wait for a token
+	TotalWait  time.Duration // Total time spent waiting for tokens
+	MaxWait    time.Duration // Longest wait for a token
+}
+
+// AverageWait returns the average time an execution waited for a token,
+// executions that didn't wait included.
+func (s RateLimitStats) AverageWait() time.Duration {
+	if s.Executions == 0 {
+		return 0
+	}
+	return s.TotalWait / time.Duration(s.Executions)
+}
+
+// tokenBucket is a token bucket rate limiter. Tokens are reserved in call
+// order, the bucket going into debt when empty, so that callers are served
+// in turn without polling.
+type tokenBucket struct {
+	mu     sync.Mutex
+	period time.Duration // Time to produce a token
+	burst  float64
+	tokens float64
+	last   time.Time
+	stats  RateLimitStats
+}
+
+// newTokenBucket creates a full bucket producing tokens per interval,
+// holding up to burst tokens
+func newTokenBucket(tokens int, interval time.Duration, burst int) *tokenBucket {
+	return &tokenBucket{
+		period: interval / time.Duration(tokens),
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// acquire blocks until a token is available and takes it.
+// It returns the context error if ctx is done before the token is taken.
+func (b *tokenBucket) acquire(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	b.mu.Lock()
+	wait := b.reserve(time.Now())
+	b.mu.Unlock()
+
+	if wait > 0 {
+		timer := time.NewTimer(wait)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			// Give the reserved token back
+			b.mu.Lock()
+			b.tokens = min(b.tokens+1, b.burst)
+			b.mu.Unlock()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.stats.Executions++
+	if wait > 0 {
+		b.stats.Delayed++
+		b.stats.TotalWait += wait
+		b.stats.MaxWait = max(b.stats.MaxWait, wait)
+	}
+	return nil
+}
+
+// reserve takes a token and returns how long to wait until it is produced.
+// Must be called with mu held.
+func (b *tokenBucket) reserve(now time.Time) time.Duration {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = min(b.tokens+float64(elapsed)/float64(b.period), b.burst)
+		b.last = now
+	}
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens * float64(b.period))
+}
+
+// RateLimitStats returns statistics about the waits for the rate limit.
+//
+// Returns:
+//   - RateLimitStats: The statistics, zero without WithRateLimit
+func (c *ConMan[T]) RateLimitStats() RateLimitStats {
+	if c.bucket == nil {
+		return RateLimitStats{}
+	}
+	c.bucket.mu.Lock()
+	defer c.bucket.mu.Unlock()
+	return c.bucket.stats
+}
@@ -0,0 +1,86 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	b := newTokenBucket(100, time.Second, 2)
+
+	start := time.Now()
+	for range 2 {
+		if err := b.acquire(ctx); err != nil {
+			t.Fatalf("acquire returned an unexpected error: %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Millisecond {
+		t.Errorf("Expected the burst to be served right away, took %v", elapsed)
+	}
+	if err := b.acquire(ctx); err != nil {
+		t.Fatalf("acquire returned an unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 8*time.Millisecond {
+		t.Errorf("Expected the third token to take about 10ms, took %v", elapsed)
+	}
+	if s := b.stats; s.Executions != 3 || s.Delayed != 1 || s.MaxWait <= 0 || s.TotalWait != s.MaxWait {
+		t.Errorf("Expected a single delayed execution, got %+v", s)
+	}
+}
+
+func TestTokenBucketCancellation(t *testing.T) {
+	t.Parallel()
+	b := newTokenBucket(1, time.Hour, 1)
+	if err := b.acquire(t.Context()); err != nil {
+		t.Fatalf("acquire returned an unexpected error: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.acquire(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected the context error, got %v", err)
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.tokens < -0.01 || b.stats.Executions != 1 {
+		t.Errorf("Expected the reserved token to be given back, got %v tokens and %+v", b.tokens, b.stats)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4, WithRateLimit(1, 20*time.Millisecond, 1))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	start := time.Now()
+	for i := range 5 {
+		cm.Run(ctx, &doubler{operand: i})
+	}
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 75*time.Millisecond {
+		t.Errorf("Expected 5 executions to take at least 80ms, took %v", elapsed)
+	}
+	if len(cm.Outputs()) != 5 {
+		t.Errorf("Expected 5 outputs, got %d", len(cm.Outputs()))
+	}
+
+	s := cm.RateLimitStats()
+	if s.Executions != 5 || s.Delayed != 4 {
+		t.Errorf("Expected 4 of the 5 executions to wait, got %+v", s)
+	}
+	if s.MaxWait < 60*time.Millisecond || s.AverageWait() <= 0 {
+		t.Errorf("Expected the last execution to wait for about 80ms, got %+v", s)
+	}
+}
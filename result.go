@@ -0,0 +1,27 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import "time"
+
+// Result holds the outcome of a single task along with its execution metadata.
+type Result[T any] struct {
+	Seq      int       // Submission sequence number, starting at 0
+	Task     Task[T]   // The task that produced this result
+	Value    T         // Value returned by the task, only meaningful if Err is nil
+	Err      error     // Execution error, nil if the task succeeded
+	Attempts int       // Number of times the task was executed, including retries
+	Start    time.Time // Time at which the first execution started
+	End      time.Time // Time at which the last execution ended
+}
+
+// Failed reports whether the task failed.
+func (r Result[T]) Failed() bool {
+	return r.Err != nil
+}
+
+// Duration returns the total time spent on the task, including retry delays.
+func (r Result[T]) Duration() time.Duration {
+	return r.End.Sub(r.Start)
+}
@@ -3,16 +3,57 @@
 
 package conman
 
-import "fmt"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrMaxElapsed is the reason of a RetryError when a task ran out of the
+// total time allowed by RetryConfig.MaxElapsed.
+var ErrMaxElapsed = errors.New("conman: retry time limit exceeded")
+
+// attemptKey is the context key of the attempt number
+type attemptKey struct{}
+
+// Attempt returns the number of the current execution of a task, starting at 1
+// for the first execution and increasing with each retry. It is meant to be
+// called from Execute, with the context received by the task.
+//
+// Returns:
+//   - int: The attempt number, 0 if ctx doesn't come from a task run by a ConMan
+//
+// Example:
+//
+//	func (t *upload) Execute(ctx context.Context) (int, error) {
+//		if conman.Attempt(ctx) > 1 {
+//			log.Printf("retrying upload of %s", t.name)
+//		}
+//		// ...
+//	}
+func Attempt(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
 
 // RetryConfig defines the retry behavior for operations that may fail temporarily.
 // It includes parameters for controlling the number of attempts, delays, and backoff strategy.
+//
+// The delays are computed by Backoff if set. Otherwise, they grow exponentially
+// from InitialDelay by BackoffFactor, up to MaxDelay, with optional full jitter,
+// as computed by an ExponentialBackoff.
 type RetryConfig struct {
 	MaxAttempts   int     // Maximum number of retry attempts
 	InitialDelay  int64   // Initial delay in milliseconds
 	BackoffFactor float64 // Multiplier for exponential backoff
 	MaxDelay      int64   // Maximum delay in milliseconds
 	Jitter        bool    // Whether to add random jitter to delays
+	MaxElapsed    int64   // Maximum total time in milliseconds across all attempts, 0 for no limit
+	Backoff       Backoff // Computes the delays, replacing the fields above when set
 }
 
 // validate checks the validity of the RetryConfig fields.
@@ -22,6 +63,13 @@ func (rc *RetryConfig) validate() error {
 	if rc.MaxAttempts <= 0 {
 		return fmt.Errorf("MaxAttempts must be positive, got %d", rc.MaxAttempts)
 	}
+	if rc.MaxElapsed < 0 {
+		return fmt.Errorf("MaxElapsed cannot be negative, got %d", rc.MaxElapsed)
+	}
+	if rc.Backoff != nil {
+		// The delay fields are not used
+		return nil
+	}
 	if rc.InitialDelay < 0 {
 		return fmt.Errorf("InitialDelay cannot be negative, got %d", rc.InitialDelay)
 	}
@@ -44,9 +92,20 @@ func (rc *RetryConfig) validate() error {
 
 // RetriableError is an error type that indicates a task should be retried.
 // It includes an embedded RetryConfig to specify the retry strategy.
+//
+// The error of each attempt is classified again. If a retry fails with an error
+// that isn't a *RetriableError, the task isn't retried anymore. If it fails with
+// a *RetriableError holding another RetryConfig, that config is used for the
+// next attempts, the attempts already made counting toward its MaxAttempts.
+//
+// RetryAfter and RetryAt carry a hint from the failing service, such as the
+// Retry-After header of an HTTP 429 or 503 response. When set, the hint
+// replaces the delay computed from the RetryConfig before the next attempt.
 type RetriableError struct {
 	Err         error
 	RetryConfig *RetryConfig
+	RetryAfter  time.Duration // Delay to wait before the next attempt, if positive
+	RetryAt     time.Time     // Time of the next attempt, if not zero and RetryAfter isn't set
 }
 
 // Error returns the error message of the underlying error.
@@ -54,6 +113,126 @@ func (e *RetriableError) Error() string {
 	return e.Err.Error()
 }
 
+// Unwrap returns the underlying error.
+func (e *RetriableError) Unwrap() error {
+	return e.Err
+}
+
+// backoff returns the Backoff computing the delays before the retry attempts.
+// Without Backoff, the delay fields make an ExponentialBackoff from InitialDelay
+// by BackoffFactor, up to MaxDelay, with full jitter if Jitter is set.
+func (rc *RetryConfig) backoff() Backoff {
+	if rc.Backoff != nil {
+		return rc.Backoff
+	}
+	if rc.MaxDelay <= 0 {
+		// The delays are capped at MaxDelay, so there is no delay at all
+		return &ConstantBackoff{}
+	}
+	var b Backoff = &ExponentialBackoff{
+		Initial: time.Duration(rc.InitialDelay) * time.Millisecond,
+		Factor:  rc.BackoffFactor,
+		Max:     time.Duration(rc.MaxDelay) * time.Millisecond,
+	}
+	if rc.Jitter {
+		b = &jitteredBackoff{b}
+	}
+	return b
+}
+
+// jitteredBackoff waits a random delay between 0 and the delay of another backoff
+type jitteredBackoff struct {
+	Backoff
+}
+
+// Next implements Backoff.
+func (b *jitteredBackoff) Next(attempt int) time.Duration {
+	return randomDelay(0, b.Backoff.Next(attempt))
+}
+
+// RetryError is the error recorded for a task whose retries were stopped
+// before it succeeded. It wraps both the reason retries were stopped
+// (e.g. ErrMaxElapsed) and the error of the last attempt.
+type RetryError struct {
+	Attempts int   // Number of executions of the task, the first one included
+	Err      error // Error of the last attempt
+	Reason   error // Reason retries were stopped
+}
+
+// Error returns the reason, the number of attempts and the last error.
+func (e *RetryError) Error() string {
+	return fmt.Sprintf("%v after %d attempts: %v", e.Reason, e.Attempts, e.Err)
+}
+
+// Unwrap returns the reason and the error of the last attempt.
+func (e *RetryError) Unwrap() []error {
+	return []error{e.Reason, e.Err}
+}
+
+// WithRetryAfter sets the retry hint from the value of a Retry-After header.
+// A zero delay or a past date requests an immediate retry.
+// An empty or invalid value is ignored.
+// Returns the RetriableError for method chaining.
+//
+// Example:
+//
+//	if resp.StatusCode == http.StatusTooManyRequests {
+//		err := &conman.RetriableError{Err: errors.New("rate limited")}
+//		return -1, err.WithExponentialBackoff().WithRetryAfter(resp.Header.Get("Retry-After"))
+//	}
+func (e *RetriableError) WithRetryAfter(value string) *RetriableError {
+	d, err := ParseRetryAfter(value)
+	switch {
+	case err != nil:
+	case d > 0:
+		e.RetryAfter, e.RetryAt = d, time.Time{}
+	default:
+		// A zero RetryAfter isn't a hint, an immediate retry is requested through RetryAt
+		e.RetryAfter, e.RetryAt = 0, time.Now()
+	}
+	return e
+}
+
+// retryHint returns the delay requested by a *RetriableError in the err
+// chain before the next attempt, if any
+func retryHint(err error) (time.Duration, bool) {
+	var e *RetriableError
+	if !errors.As(err, &e) {
+		return 0, false
+	}
+	if e.RetryAfter > 0 {
+		return e.RetryAfter, true
+	}
+	if !e.RetryAt.IsZero() {
+		return max(time.Until(e.RetryAt), 0), true
+	}
+	return 0, false
+}
+
+// ParseRetryAfter parses the value of a Retry-After HTTP header, either a
+// number of seconds or an HTTP date.
+//
+// Parameters:
+//   - value: The header value, e.g. "120" or "Wed, 21 Oct 2026 07:28:00 GMT"
+//
+// Returns:
+//   - time.Duration: The delay to wait, 0 if the date is in the past
+//   - error: An error if the value is neither a number of seconds nor a date
+func ParseRetryAfter(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		if seconds < 0 {
+			return 0, fmt.Errorf("invalid Retry-After value %q: negative delay", value)
+		}
+		return capDelay(float64(seconds)*float64(time.Second), 0), nil
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid Retry-After value %q", value)
+	}
+	return max(time.Until(date), 0), nil
+}
+
 func (e *RetriableError) WithRetryConfig(config *RetryConfig) (*RetriableError, error) {
 	if err := config.validate(); err != nil {
 		return nil, err
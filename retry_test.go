@@ -1,7 +1,11 @@
 package conman
 
 import (
+	"math"
+	"net/http"
+	"slices"
 	"testing"
+	"time"
 )
 
 func TestRetryConfigValidate(t *testing.T) {
@@ -66,6 +70,18 @@ func TestRetryConfigValidate(t *testing.T) {
 			expectError: true,
 			errorMsg:    "MaxDelay cannot be negative, got -5000",
 		},
+		{
+			name: "negative max elapsed",
+			config: RetryConfig{
+				MaxAttempts:   5,
+				InitialDelay:  100,
+				BackoffFactor: 2.0,
+				MaxDelay:      5000,
+				MaxElapsed:    -1,
+			},
+			expectError: true,
+			errorMsg:    "MaxElapsed cannot be negative, got -1",
+		},
 		{
 			name: "initial delay greater than max delay",
 			config: RetryConfig{
@@ -130,6 +146,16 @@ func TestRetryConfigValidate(t *testing.T) {
 			},
 			expectError: false,
 		},
+		{
+			name: "delay fields ignored with a backoff",
+			config: RetryConfig{
+				MaxAttempts:   3,
+				InitialDelay:  100,
+				BackoffFactor: 0.0,
+				Backoff:       &ConstantBackoff{},
+			},
+			expectError: false,
+		},
 		{
 			name: "minimal valid config",
 			config: RetryConfig{
@@ -160,3 +186,72 @@ func TestRetryConfigValidate(t *testing.T) {
 		})
 	}
 }
+
+func TestRetryConfigBackoff(t *testing.T) {
+	t.Parallel()
+	ms := time.Millisecond
+	exponential := &RetryConfig{MaxAttempts: 5, InitialDelay: 100, BackoffFactor: 2.0, MaxDelay: 500}
+	if d := delays(exponential.backoff(), 5); !slices.Equal(d, []time.Duration{100 * ms, 200 * ms, 400 * ms, 500 * ms, 500 * ms}) {
+		t.Errorf("Expected the delays to grow exponentially up to MaxDelay, got %v", d)
+	}
+	uncapped := &RetryConfig{MaxAttempts: 3, InitialDelay: 100, BackoffFactor: 2.0}
+	if d := delays(uncapped.backoff(), 3); !slices.Equal(d, []time.Duration{0, 0, 0}) {
+		t.Errorf("Expected a zero MaxDelay to cap the delays at 0, got %v", d)
+	}
+	huge := &RetryConfig{MaxAttempts: 5000, InitialDelay: 1000, BackoffFactor: 10.0, MaxDelay: math.MaxInt64}
+	if d := huge.backoff().Next(1000); d <= 0 {
+		t.Errorf("Expected a huge positive delay, got %v", d)
+	}
+
+	jittered := &RetryConfig{MaxAttempts: 5, InitialDelay: 100, BackoffFactor: 2.0, MaxDelay: 500, Jitter: true}
+	b := jittered.backoff()
+	for range 100 {
+		for attempt, ceiling := range delays(exponential.backoff(), 5) {
+			if d := b.Next(attempt); d < 0 || d >= ceiling {
+				t.Fatalf("Expected a jittered delay in [0, %v) for attempt %d, got %v", ceiling, attempt, d)
+			}
+		}
+	}
+
+	custom := &ConstantBackoff{Delay: ms}
+	if b := (&RetryConfig{MaxAttempts: 1, InitialDelay: 100, MaxDelay: 500, Backoff: custom}).backoff(); b != custom {
+		t.Errorf("Expected Backoff to replace the delay fields, got %v", b)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Parallel()
+	if d, err := ParseRetryAfter(" 120 "); err != nil || d != 2*time.Minute {
+		t.Errorf("Expected 2m, got %v (%v)", d, err)
+	}
+	date := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if d, err := ParseRetryAfter(date); err != nil || d <= 59*time.Minute || d > time.Hour {
+		t.Errorf("Expected about an hour, got %v (%v)", d, err)
+	}
+	if d, err := ParseRetryAfter("Wed, 21 Oct 2015 07:28:00 GMT"); err != nil || d != 0 {
+		t.Errorf("Expected no delay for a past date, got %v (%v)", d, err)
+	}
+	for _, value := range []string{"", "-5", "soon"} {
+		if _, err := ParseRetryAfter(value); err == nil {
+			t.Errorf("Expected an error for %q", value)
+		}
+	}
+}
+
+func TestWithRetryAfter(t *testing.T) {
+	t.Parallel()
+	e := (&RetriableError{RetryAt: time.Now()}).WithRetryAfter("3")
+	if e.RetryAfter != 3*time.Second || !e.RetryAt.IsZero() {
+		t.Errorf("Expected the header to set the hint, got %v and %v", e.RetryAfter, e.RetryAt)
+	}
+	e = (&RetriableError{RetryAfter: time.Second}).WithRetryAfter("later")
+	if e.RetryAfter != time.Second {
+		t.Errorf("Expected an invalid header to be ignored, got %v", e.RetryAfter)
+	}
+	for _, value := range []string{"0", "Wed, 21 Oct 2015 07:28:00 GMT"} {
+		e = (&RetriableError{RetryAfter: time.Second}).WithRetryAfter(value)
+		if d, ok := retryHint(e); !ok || d != 0 {
+			t.Errorf("Expected %q to request an immediate retry, got %v (hint: %v)", value, d, ok)
+		}
+	}
+}
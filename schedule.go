@@ -0,0 +1,88 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import "time"
+
+// ticket holds the scheduling attributes of a waiting item
+type ticket struct {
+	priority int
+	enqueued time.Duration // Time elapsed between the scheduler creation and the enqueuing
+	arrival  uint64        // Arrival order
+	index    int           // Position in the queue, -1 once dequeued
+}
+
+// schedule returns the ticket itself, so that types embedding a ticket
+// can be stored in a pqueue
+func (t *ticket) schedule() *ticket {
+	return t
+}
+
+// scheduler orders waiting items by decreasing priority, then arrival order.
+// A non-zero aging raises the priority of items by one level per aging period.
+type scheduler struct {
+	aging    time.Duration
+	epoch    time.Time
+	arrivals uint64
+}
+
+// newScheduler creates a scheduler with the given aging period
+func newScheduler(aging time.Duration) *scheduler {
+	return &scheduler{aging: aging, epoch: time.Now()}
+}
+
+// stamp initializes a ticket for an item arriving now
+func (s *scheduler) stamp(t *ticket, priority int) {
+	t.priority = priority
+	t.enqueued = time.Since(s.epoch)
+	t.arrival = s.arrivals
+	s.arrivals++
+}
+
+// less reports whether ticket a must be served before ticket b.
+// With aging, the effective priority of an item is its priority plus the
+// number of aging periods it has been waiting for. Since all items age at
+// the same pace, comparing priority - enqueued/aging gives the same order.
+func (s *scheduler) less(a, b *ticket) bool {
+	if s.aging > 0 {
+		sa := float64(a.priority) - float64(a.enqueued)/float64(s.aging)
+		sb := float64(b.priority) - float64(b.enqueued)/float64(s.aging)
+		if sa != sb {
+			return sa > sb
+		}
+	} else if a.priority != b.priority {
+		return a.priority > b.priority
+	}
+	return a.arrival < b.arrival
+}
+
+// pqueue is a priority queue implementing heap.Interface, ordered by a scheduler
+type pqueue[E interface{ schedule() *ticket }] struct {
+	items []E
+	sched *scheduler
+}
+
+func (q *pqueue[E]) Len() int { return len(q.items) }
+func (q *pqueue[E]) Less(i, j int) bool {
+	return q.sched.less(q.items[i].schedule(), q.items[j].schedule())
+}
+func (q *pqueue[E]) Swap(i, j int) {
+	q.items[i], q.items[j] = q.items[j], q.items[i]
+	q.items[i].schedule().index = i
+	q.items[j].schedule().index = j
+}
+func (q *pqueue[E]) Push(x any) {
+	e := x.(E)
+	e.schedule().index = len(q.items)
+	q.items = append(q.items, e)
+}
+func (q *pqueue[E]) Pop() any {
+	n := len(q.items)
+	e := q.items[n-1]
+	var zero E
+	q.items[n-1] = zero
+	q.items = q.items[:n-1]
+	e.schedule().index = -1
+	return e
+}
@@ -0,0 +1,65 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTaskTimeout is the error recorded for a task execution that exceeded its
+// timeout (see WithTaskTimeout and TimeLimited).
+var ErrTaskTimeout = errors.New("conman: task timed out")
+
+// TimeLimited can be implemented by tasks to override the default task timeout.
+//
+// Tasks that don't implement this interface use the timeout set through
+// WithTaskTimeout, if any.
+type TimeLimited interface {
+	// Timeout returns the maximum duration of an execution of the task,
+	// zero or less for no timeout.
+	Timeout() time.Duration
+}
+
+// timeout returns the timeout of an execution of the task, zero for none
+func (c *ConMan[T]) timeout(t Task[T]) time.Duration {
+	if tl, ok := t.(TimeLimited); ok {
+		return tl.Timeout()
+	}
+	return c.opts.timeout
+}
+
+// executeWithTimeout runs a single attempt of a task within its timeout, if any.
+// An attempt that fails after the timeout fires is reported with ErrTaskTimeout,
+// made retriable if configured so. The retry hint of a *RetriableError returned
+// by the task is kept.
+func (c *ConMan[T]) executeWithTimeout(ctx context.Context, t Task[T]) (T, error) {
+	d := c.timeout(t)
+	if d <= 0 {
+		return c.protectedExecute(ctx, t)
+	}
+	ctx, cancel := context.WithTimeoutCause(ctx, d, ErrTaskTimeout)
+	defer cancel()
+	op, err := c.protectedExecute(ctx, t)
+	if err == nil || !errors.Is(context.Cause(ctx), ErrTaskTimeout) {
+		return op, err
+	}
+
+	retriable := RetriableError{RetryConfig: c.opts.timeoutRetry}
+	if re, ok := err.(*RetriableError); ok {
+		err = re.Err
+		retriable.RetryAfter, retriable.RetryAt = re.RetryAfter, re.RetryAt
+		if re.RetryConfig != nil {
+			retriable.RetryConfig = re.RetryConfig
+		}
+	}
+	err = fmt.Errorf("%w after %v: %w", ErrTaskTimeout, d, err)
+	if retriable.RetryConfig != nil {
+		retriable.Err = err
+		err = &retriable
+	}
+	return op, err
+}
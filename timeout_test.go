@@ -0,0 +1,169 @@
+// Author: Ilyess Bachiri
+// Copyright (c) 2026-present Ilyess Bachiri
+
+package conman
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// limitedtask is a sleepydoubler with a timeout of its own
+type limitedtask struct {
+	sleepydoubler
+	timeout time.Duration
+}
+
+func (t *limitedtask) Timeout() time.Duration {
+	return t.timeout
+}
+
+// slowstarter times out on its first executions, then succeeds
+type slowstarter struct {
+	slow  int32
+	calls atomic.Int32
+}
+
+func (s *slowstarter) Execute(ctx context.Context) (int, error) {
+	if s.calls.Add(1) <= s.slow {
+		<-ctx.Done()
+		return -1, ctx.Err()
+	}
+	return 42, nil
+}
+
+func TestTaskTimeout(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](4, WithTaskTimeout(20*time.Millisecond))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &sleepydoubler{delay: time.Second, operand: 1})
+	cm.Run(ctx, &sleepydoubler{delay: time.Millisecond, operand: 2})
+	cm.Run(ctx, &limitedtask{sleepydoubler{delay: 50 * time.Millisecond, operand: 3}, time.Second})
+	cm.Run(ctx, &limitedtask{sleepydoubler{delay: 50 * time.Millisecond, operand: 4}, 0})
+	start := time.Now()
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected the slow task to be interrupted, waited %v", elapsed)
+	}
+
+	if len(cm.Errors()) != 1 {
+		t.Fatalf("Expected 1 error, got %v", cm.Errors())
+	}
+	err = cm.Errors()[0]
+	if !errors.Is(err, ErrTaskTimeout) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+	if len(cm.Outputs()) != 3 {
+		t.Errorf("Expected the other tasks to succeed, got %v", cm.Outputs())
+	}
+}
+
+func TestTaskTimeoutOverride(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &limitedtask{sleepydoubler{delay: time.Second, operand: 1}, 10 * time.Millisecond})
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if len(cm.Errors()) != 1 || !errors.Is(cm.Errors()[0], ErrTaskTimeout) {
+		t.Errorf("Expected the task timeout to apply without a default timeout, got %v", cm.Errors())
+	}
+}
+
+func TestTaskTimeoutCallerCancellation(t *testing.T) {
+	t.Parallel()
+	cm, err := New[int](2, WithTaskTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+	cm.Run(ctx, &sleepydoubler{delay: time.Second, operand: 1})
+	cm.Wait(t.Context())
+	if len(cm.Errors()) != 1 || errors.Is(cm.Errors()[0], ErrTaskTimeout) {
+		t.Errorf("Expected the caller's deadline not to count as a task timeout, got %v", cm.Errors())
+	}
+}
+
+func TestTimeoutRetry(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2,
+		WithTaskTimeout(10*time.Millisecond),
+		WithTimeoutRetry(&RetryConfig{MaxAttempts: 3}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	cm.Run(ctx, &slowstarter{slow: 2})
+	cm.Run(ctx, &slowstarter{slow: 5})
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+
+	for _, r := range cm.Results() {
+		switch r.Task.(*slowstarter).slow {
+		case 2:
+			if r.Err != nil || r.Attempts != 3 {
+				t.Errorf("Expected the task to succeed on its third attempt, got %+v", r)
+			}
+		case 5:
+			if !errors.Is(r.Err, ErrTaskTimeout) || r.Attempts != 4 {
+				t.Errorf("Expected the task to time out after 4 attempts, got %+v", r)
+			}
+		}
+	}
+}
+
+// hintedsleeper times out on its first execution with a retry hint, then succeeds
+type hintedsleeper struct {
+	calls atomic.Int32
+}
+
+func (h *hintedsleeper) Execute(ctx context.Context) (int, error) {
+	if h.calls.Add(1) == 1 {
+		<-ctx.Done()
+		return -1, &RetriableError{Err: ctx.Err(), RetryAfter: 10 * time.Millisecond}
+	}
+	return 42, nil
+}
+
+func TestTimeoutRetryHint(t *testing.T) {
+	t.Parallel()
+	ctx := t.Context()
+	cm, err := New[int](2,
+		WithTaskTimeout(10*time.Millisecond),
+		WithTimeoutRetry(&RetryConfig{MaxAttempts: 2, InitialDelay: 3000, BackoffFactor: 1.0, MaxDelay: 3000}),
+	)
+	if err != nil {
+		t.Fatalf("Failed to create ConMan: %v", err)
+	}
+
+	start := time.Now()
+	cm.Run(ctx, &hintedsleeper{})
+	if err := cm.Wait(ctx); err != nil {
+		t.Fatalf("ConMan Wait returned an unexpected error: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected the retry hint to replace the 3s delay, took %v", elapsed)
+	}
+	if r := cm.Results(); len(r) != 1 || r[0].Err != nil || r[0].Attempts != 2 {
+		t.Errorf("Expected the task to succeed on its second attempt, got %+v", r)
+	}
+}